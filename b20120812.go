@@ -0,0 +1,334 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+)
+
+// b20120812 changes the packet header to contain a compression flag,
+// which is followed by the (usually uncompressed) packet data.
+// The packet ids are also no longer shifted around by the "IrcJoin" packet.
+// Spectator frames now contain the full button state, instead of two mouse buttons.
+// User stats start with their completeness, and contain the full presence of the
+// user at the end. Match ids are sent as a short, instead of a single byte.
+type b20120812 struct {
+	*b323
+}
+
+func (client *b20120812) WritePacket(stream io.Writer, packetId uint16, data []byte) error {
+	// Convert packetId back for the client
+	packetId = client.BanchoIO.ConvertOutputPacketId(packetId)
+	writer := bytes.NewBuffer([]byte{})
+
+	err := writeUint16(writer, packetId)
+	if err != nil {
+		return err
+	}
+
+	// Packet data is sent uncompressed by default
+	err = writeBoolean(writer, false)
+	if err != nil {
+		return err
+	}
+
+	err = writeUint32(writer, uint32(len(data)))
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = stream.Write(writer.Bytes())
+	return err
+}
+
+func (client *b20120812) ReadPacketHeader(stream io.Reader) (header PacketHeader, err error) {
+	header.Id, err = readUint16(stream)
+	if err != nil {
+		return header, err
+	}
+
+	// Convert packet ID to a usable value
+	header.Id = client.BanchoIO.ConvertInputPacketId(header.Id)
+
+	header.Compressed, err = readBoolean(stream)
+	if err != nil {
+		return header, shortPacketError(err)
+	}
+
+	header.Length, err = readInt32(stream)
+	return header, shortPacketError(err)
+}
+
+func (client *b20120812) ConvertInputPacketId(packetId uint16) uint16 {
+	return packetId
+}
+
+func (client *b20120812) ConvertOutputPacketId(packetId uint16) uint16 {
+	return packetId
+}
+
+func (client *b20120812) WriteSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteFrameBundle(writer, bundle)
+	return client.BanchoIO.WritePacket(stream, BanchoSpectateFrames, writer.Bytes())
+}
+
+func (client *b20120812) WriteOsuSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteFrameBundle(writer, bundle)
+	return client.BanchoIO.WritePacket(stream, OsuSpectateFrames, writer.Bytes())
+}
+
+func (client *b20120812) WriteFrameBundle(writer io.Writer, bundle ReplayFrameBundle) error {
+	writeInt32(writer, bundle.Extra)
+	writeUint16(writer, uint16(len(bundle.Frames)))
+
+	for _, frame := range bundle.Frames {
+		writeUint8(writer, frame.ButtonState)
+		writeUint8(writer, 0) // legacy button byte
+		writeFloat32(writer, frame.MouseX)
+		writeFloat32(writer, frame.MouseY)
+		writeInt32(writer, frame.Time)
+	}
+
+	writeUint8(writer, bundle.Action)
+
+	if bundle.Frame != nil {
+		client.WriteScoreFrame(writer, *bundle.Frame)
+	}
+	return nil
+}
+
+func (client *b20120812) WriteMatchScoreUpdate(stream io.Writer, frame ScoreFrame) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteScoreFrame(writer, frame)
+	return client.BanchoIO.WritePacket(stream, BanchoMatchScoreUpdate, writer.Bytes())
+}
+
+func (client *b20120812) WriteOsuMatchScoreUpdate(stream io.Writer, frame ScoreFrame) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteScoreFrame(writer, frame)
+	return client.BanchoIO.WritePacket(stream, OsuMatchScoreUpdate, writer.Bytes())
+}
+
+func (client *b20120812) WriteScoreFrame(writer io.Writer, frame ScoreFrame) error {
+	client.b323.WriteScoreFrame(writer, frame)
+	return writeUint8(writer, frame.TagByte)
+}
+
+func (client *b20120812) ReadScoreFrame(reader io.Reader) (*ScoreFrame, error) {
+	frame, err := client.b323.ReadScoreFrame(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := newFieldReader(reader, "ScoreFrame")
+	frame.TagByte = readField(fields, "TagByte", readUint8)
+
+	return frame, fields.Err()
+}
+
+func (client *b20120812) ConvertButtonState(state uint8) ButtonStateConversion {
+	// The full button state is sent, so nothing will be lost
+	return ButtonStateConversion{ButtonState: state}
+}
+
+func (client *b20120812) ReadFrameBundle(reader io.Reader) (*ReplayFrameBundle, error) {
+	fields := newFieldReader(reader, "ReplayFrameBundle")
+	bundle := &ReplayFrameBundle{}
+	bundle.Extra = readField(fields, "Extra", readInt32)
+	count := readField(fields, "FrameCount", readUint16)
+	bundle.Frames = readList(fields, "Frames", int(count), client.ReadReplayFrame)
+	bundle.Action = readField(fields, "Action", readUint8)
+
+	if fields.Err() != nil || !hasRemainingData(reader) {
+		// The score frame is only sent while playing
+		return bundle, fields.Err()
+	}
+
+	bundle.Frame = readField(fields, "Frame", client.BanchoIO.ReadScoreFrame)
+
+	return bundle, fields.Err()
+}
+
+func (client *b20120812) ReadReplayFrame(reader io.Reader) (*ReplayFrame, error) {
+	fields := newFieldReader(reader, "ReplayFrame")
+	frame := &ReplayFrame{}
+	frame.ButtonState = readField(fields, "ButtonState", readUint8)
+	readField(fields, "LegacyButtonState", readUint8)
+	frame.MouseX = readField(fields, "MouseX", readFloat32)
+	frame.MouseY = readField(fields, "MouseY", readFloat32)
+	frame.Time = readField(fields, "Time", readInt32)
+
+	return frame, fields.Err()
+}
+
+func (client *b20120812) WriteUserStats(stream io.Writer, info UserInfo) error {
+	writer := bytes.NewBuffer([]byte{})
+
+	if info.Presence.IsIrc {
+		writeString(writer, info.Name)
+		return client.BanchoIO.WritePacket(stream, BanchoHandleIrcJoin, writer.Bytes())
+	}
+
+	client.WriteStats(writer, info)
+	return client.BanchoIO.WritePacket(stream, BanchoHandleOsuUpdate, writer.Bytes())
+}
+
+func (client *b20120812) WriteUserQuit(stream io.Writer, quit UserQuit) error {
+	writer := bytes.NewBuffer([]byte{})
+
+	if quit.Info.Presence.IsIrc && quit.QuitState != QuitStateIrcRemaining {
+		writeString(writer, quit.Info.Name)
+		return client.BanchoIO.WritePacket(stream, BanchoHandleIrcQuit, writer.Bytes())
+	}
+
+	if quit.QuitState == QuitStateOsuRemaining {
+		return nil
+	}
+
+	client.WriteStats(writer, *quit.Info)
+	return client.BanchoIO.WritePacket(stream, BanchoHandleOsuQuit, writer.Bytes())
+}
+
+func (client *b20120812) WriteStats(writer io.Writer, info UserInfo) error {
+	writeInt32(writer, info.Id)
+	writeUint8(writer, CompletenessFull)
+	client.WriteStatus(writer, info.Status)
+	writeUint64(writer, info.Stats.Rscore)
+	writeFloat32(writer, float32(info.Stats.Accuracy))
+	writeInt32(writer, info.Stats.Playcount)
+	writeUint64(writer, info.Stats.Tscore)
+	writeInt32(writer, info.Stats.Rank)
+	writeString(writer, info.Name)
+	writeString(writer, info.AvatarFilename())
+	writeUint8(writer, uint8(info.Presence.Timezone+24))
+	writeString(writer, info.Presence.Location())
+	writeUint8(writer, info.Presence.Permissions)
+	writeFloat32(writer, info.Presence.Longitude)
+	writeFloat32(writer, info.Presence.Latitude)
+	return nil
+}
+
+func (client *b20120812) ReadStats(reader io.Reader) (*UserInfo, error) {
+	fields := newFieldReader(reader, "UserInfo")
+	info := &UserInfo{
+		Presence: &UserPresence{},
+		Stats:    &UserStats{},
+	}
+
+	info.Id = readField(fields, "Id", readInt32)
+	completeness := readField(fields, "Completeness", readUint8)
+	info.Status = readField(fields, "Status", client.ReadStatus)
+
+	if completeness < CompletenessStatistics {
+		return info, fields.Err()
+	}
+
+	info.Stats.Rscore = readField(fields, "Stats.Rscore", readUint64)
+	info.Stats.Accuracy = float64(readField(fields, "Stats.Accuracy", readFloat32))
+	info.Stats.Playcount = readField(fields, "Stats.Playcount", readInt32)
+	info.Stats.Tscore = readField(fields, "Stats.Tscore", readUint64)
+	info.Stats.Rank = readField(fields, "Stats.Rank", readInt32)
+
+	if completeness < CompletenessFull {
+		return info, fields.Err()
+	}
+
+	info.Name = readField(fields, "Name", readString)
+	readField(fields, "AvatarFilename", readString)
+	timezone := readField(fields, "Presence.Timezone", readUint8)
+	info.Presence.Timezone = int8(timezone) - 24
+	location := readField(fields, "Presence.Location", readString)
+	info.Presence.Permissions = readField(fields, "Presence.Permissions", readUint8)
+	info.Presence.Longitude = readField(fields, "Presence.Longitude", readFloat32)
+	info.Presence.Latitude = readField(fields, "Presence.Latitude", readFloat32)
+
+	// Location is formatted as "<Country> / <City>"
+	country, city, _ := strings.Cut(location, " / ")
+	info.Presence.CountryIndex = GetCountryIndexFromName(country)
+	info.Presence.City = city
+
+	return info, fields.Err()
+}
+
+func (client *b20120812) WriteMatchUpdate(stream io.Writer, match Match) error {
+	return client.writeMatchPacket(stream, BanchoMatchUpdate, match)
+}
+
+func (client *b20120812) WriteMatchNew(stream io.Writer, match Match) error {
+	return client.writeMatchPacket(stream, BanchoMatchNew, match)
+}
+
+func (client *b20120812) WriteMatchJoinSuccess(stream io.Writer, match Match) error {
+	return client.writeMatchPacket(stream, BanchoMatchJoinSuccess, match)
+}
+
+func (client *b20120812) WriteMatchStart(stream io.Writer, match Match) error {
+	return client.writeMatchPacket(stream, BanchoMatchStart, match)
+}
+
+func (client *b20120812) WriteOsuMatchCreate(stream io.Writer, match Match) error {
+	return client.writeMatchPacket(stream, OsuMatchCreate, match)
+}
+
+func (client *b20120812) WriteOsuMatchChangeSettings(stream io.Writer, match Match) error {
+	return client.writeMatchPacket(stream, OsuMatchChangeSettings, match)
+}
+
+func (client *b20120812) writeMatchPacket(stream io.Writer, packetId uint16, match Match) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteMatch(writer, match)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, packetId, writer.Bytes())
+}
+
+func (client *b20120812) WriteMatch(writer io.Writer, match Match) error {
+	if match.Id < 0 || match.Id > math.MaxUint16 {
+		return &FieldError{Field: "Match.Id", Err: ErrValueOutOfRange}
+	}
+
+	writeUint16(writer, uint16(match.Id))
+	return client.writeMatchData(writer, match)
+}
+
+func (client *b20120812) ReadMatch(reader io.Reader) (*Match, error) {
+	fields := newFieldReader(reader, "Match")
+	match := &Match{}
+
+	match.Id = int32(readField(fields, "Id", readUint16))
+	client.readMatchData(fields, match)
+
+	return match, fields.Err()
+}
+
+func (client *b20120812) Clone() BanchoIO {
+	clone := client.clone()
+	clone.BanchoIO = clone
+	return clone
+}
+
+func (client *b20120812) clone() *b20120812 {
+	return &b20120812{client.b323.clone()}
+}
+
+func newB20120812() *b20120812 {
+	client := &b20120812{newB323()}
+	client.BanchoIO = client
+	client.version = 20120812
+	initB20120812Packets(client)
+	return client
+}
+
+func init() {
+	RegisterClient(20120812, newB20120812())
+}
@@ -0,0 +1,168 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestB20120812Framing(t *testing.T) {
+	client := newTestClient(t, 20120812)
+	stream := bytes.NewBuffer([]byte{})
+
+	if err := client.WriteLoginReply(stream, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	// id, compression flag, length & the uncompressed payload
+	expected := []byte{5, 0, 0, 4, 0, 0, 0, 0xE8, 0x03, 0, 0}
+	if !bytes.Equal(stream.Bytes(), expected) {
+		t.Fatalf("expected %v, got %v", expected, stream.Bytes())
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoLoginReply, int32(1000))
+
+	// Builds between the framing change & the presence split use the same header
+	stream.Reset()
+	GetClientInterface(20120815).WriteLoginReply(stream, 1000)
+	if !bytes.Equal(stream.Bytes(), expected) {
+		t.Fatalf("b20120815: expected %v, got %v", expected, stream.Bytes())
+	}
+}
+
+func TestB20120812CompressedPacket(t *testing.T) {
+	client := newB20120812()
+	compressed := compressData([]byte{0xE8, 0x03, 0, 0})
+	stream := bytes.NewBuffer([]byte{5, 0, 1})
+	writeUint32(stream, uint32(len(compressed)))
+	stream.Write(compressed)
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoLoginReply, int32(1000))
+}
+
+func TestB20120812PacketIds(t *testing.T) {
+	client := newB20120812()
+
+	// Packet ids are no longer shifted by the "IrcJoin" packet
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteSpectatorJoined(stream, 2)
+	})
+	expectPacket(t, packet, BanchoSpectatorJoined, int32(2))
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteChannelJoinSuccess(stream, "#osu")
+	})
+	expectPacket(t, packet, BanchoChannelJoinSuccess, "#osu")
+}
+
+func TestB20120812ScoreFrame(t *testing.T) {
+	client := newB20120812()
+	frame := newTestScoreFrame()
+	frame.TagByte = 4
+	frame.ScoreV2 = true
+	frame.ComboPortion = 0.5
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMatchScoreUpdate(stream, frame)
+	})
+
+	// The tag byte was added, but ScoreV2 is not sent yet
+	payload := bytes.NewBuffer([]byte{})
+	client.WriteScoreFrame(payload, frame)
+	if payload.Len() != 28 {
+		t.Fatalf("expected a score frame of 28 bytes, got %d", payload.Len())
+	}
+
+	frame.ScoreV2 = false
+	frame.ComboPortion = 0
+	expectPacket(t, packet, BanchoMatchScoreUpdate, &frame)
+}
+
+func TestB20120812UserStats(t *testing.T) {
+	client := newTestClient(t, 20120812)
+	info := UserInfo{
+		Id:   2,
+		Name: "peppy",
+		Presence: &UserPresence{
+			Timezone:     1,
+			CountryIndex: 14,
+			Permissions:  PermissionsPeppy,
+			City:         "Perth",
+			Longitude:    115.86,
+			Latitude:     -31.95,
+		},
+		Status: &UserStatus{
+			Action:          StatusPlaying,
+			Text:            "Kenji Ninuma - DISCOTHEQUE",
+			BeatmapChecksum: "a5b99395a42bd55bc5eb1d2411cbdf8b",
+			Mods:            64,
+		},
+		Stats: &UserStats{
+			Rank:      1,
+			Rscore:    5000000,
+			Tscore:    9000000,
+			Accuracy:  0.5,
+			Playcount: 120,
+		},
+	}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserStats(stream, info)
+	})
+	expectPacket(t, packet, BanchoHandleOsuUpdate, &info)
+
+	quit := UserQuit{Info: &info, QuitState: QuitStateGone}
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserQuit(stream, quit)
+	})
+	expectPacket(t, packet, BanchoHandleOsuQuit, &quit)
+}
+
+func TestB20120812UserStatsCompleteness(t *testing.T) {
+	client := newTestClient(t, 20120812)
+	payload := bytes.NewBuffer([]byte{})
+	writeInt32(payload, 2)
+	writeUint8(payload, CompletenessStatusOnly)
+	writeUint8(payload, StatusAfk)
+	writeString(payload, "")
+	writeString(payload, "")
+	writeUint16(payload, 0)
+
+	data, err := client.DecodePayload(BanchoHandleOsuUpdate, payload.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := data.(*UserInfo)
+	if info.Id != 2 || info.Status.Action != StatusAfk || *info.Stats != (UserStats{}) {
+		t.Fatalf("unexpected user info: %#v", info)
+	}
+}
+
+func TestB20120812Match(t *testing.T) {
+	client := newTestClient(t, 20120812)
+	match := newTestMatch(300)
+
+	// Match ids are sent as a short
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMatchUpdate(stream, match)
+	})
+	expectPacket(t, packet, BanchoMatchUpdate, &match)
+
+	match.Id = 70000
+	err := client.WriteMatchNew(io.Discard, match)
+
+	var fieldError *FieldError
+	if !errors.As(err, &fieldError) || !errors.Is(err, ErrValueOutOfRange) {
+		t.Fatalf("expected ErrValueOutOfRange for Match.Id, got %v", err)
+	}
+}
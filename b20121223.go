@@ -0,0 +1,214 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+)
+
+// b20121223 splits the user information into a presence packet, containing
+// the name, location & permissions, and a stats packet that only contains
+// the status & stats. The presence can also be sent as a bundle of user ids.
+// The user status now contains the mode and beatmap id as well.
+type b20121223 struct {
+	*b20120812
+}
+
+func (client *b20121223) WriteUserStats(stream io.Writer, info UserInfo) error {
+	if info.Presence.IsIrc {
+		// Irc users don't have any stats
+		return client.BanchoIO.WriteUserPresence(stream, info)
+	}
+
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteStats(writer, info)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, BanchoHandleOsuUpdate, writer.Bytes())
+}
+
+func (client *b20121223) WriteStats(writer io.Writer, info UserInfo) error {
+	writeInt32(writer, info.Id)
+
+	err := client.WriteStatus(writer, info.Status)
+	if err != nil {
+		return err
+	}
+
+	writeUint64(writer, info.Stats.Rscore)
+	writeFloat32(writer, float32(info.Stats.Accuracy))
+	writeInt32(writer, info.Stats.Playcount)
+	writeUint64(writer, info.Stats.Tscore)
+	writeInt32(writer, info.Stats.Rank)
+	writeUint16(writer, info.Stats.PP)
+	return nil
+}
+
+func (client *b20121223) WriteUserQuit(stream io.Writer, quit UserQuit) error {
+	writer := bytes.NewBuffer([]byte{})
+
+	if quit.Info.Presence.IsIrc && quit.QuitState != QuitStateIrcRemaining {
+		writeString(writer, quit.Info.Name)
+		return client.BanchoIO.WritePacket(stream, BanchoHandleIrcQuit, writer.Bytes())
+	}
+
+	if quit.QuitState == QuitStateOsuRemaining {
+		return nil
+	}
+
+	err := client.WriteStats(writer, *quit.Info)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, BanchoHandleOsuQuit, writer.Bytes())
+}
+
+func (client *b20121223) WriteStatus(writer io.Writer, status *UserStatus) error {
+	return MarshalTo(writer, status, client.version)
+}
+
+func (client *b20121223) WriteUserPresence(stream io.Writer, info UserInfo) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WritePresence(writer, info)
+	return client.BanchoIO.WritePacket(stream, BanchoUserPresence, writer.Bytes())
+}
+
+func (client *b20121223) WritePresence(writer io.Writer, info UserInfo) error {
+	var mode uint8
+	if info.Status != nil {
+		mode = info.Status.Mode
+	}
+
+	var rank int32
+	if info.Stats != nil {
+		rank = info.Stats.Rank
+	}
+
+	writeInt32(writer, info.Id)
+	writeString(writer, info.Name)
+	writeUint8(writer, uint8(info.Presence.Timezone+24))
+	writeUint8(writer, uint8(info.Presence.CountryIndex))
+	writeUint8(writer, info.Presence.Permissions|mode<<5)
+	writeFloat32(writer, info.Presence.Longitude)
+	writeFloat32(writer, info.Presence.Latitude)
+	writeInt32(writer, rank)
+	return nil
+}
+
+func (client *b20121223) WriteUserPresenceSingle(stream io.Writer, info UserInfo) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeInt32(writer, info.Id)
+	return client.BanchoIO.WritePacket(stream, BanchoUserPresenceSingle, writer.Bytes())
+}
+
+func (client *b20121223) WriteUserPresenceBundle(stream io.Writer, infos []UserInfo) error {
+	userIds := make([]int32, len(infos))
+	for i, info := range infos {
+		userIds[i] = info.Id
+	}
+
+	writer := bytes.NewBuffer([]byte{})
+	writeIntList16(writer, userIds)
+	return client.BanchoIO.WritePacket(stream, BanchoUserPresenceBundle, writer.Bytes())
+}
+
+func (client *b20121223) WriteOsuUserStatus(stream io.Writer, status UserStatus) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteStatus(writer, &status)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, OsuSendUserStatus, writer.Bytes())
+}
+
+func (client *b20121223) ReadStatus(reader io.Reader) (*UserStatus, error) {
+	status := &UserStatus{}
+	err := UnmarshalFrom(reader, status, client.version)
+	return status, err
+}
+
+func (client *b20121223) ReadStats(reader io.Reader) (*UserInfo, error) {
+	fields := newFieldReader(reader, "UserInfo")
+	info := &UserInfo{
+		Presence: &UserPresence{},
+		Stats:    &UserStats{},
+	}
+
+	info.Id = readField(fields, "Id", readInt32)
+	info.Status = readField(fields, "Status", client.ReadStatus)
+	info.Stats.Rscore = readField(fields, "Stats.Rscore", readUint64)
+	info.Stats.Accuracy = float64(readField(fields, "Stats.Accuracy", readFloat32))
+	info.Stats.Playcount = readField(fields, "Stats.Playcount", readInt32)
+	info.Stats.Tscore = readField(fields, "Stats.Tscore", readUint64)
+	info.Stats.Rank = readField(fields, "Stats.Rank", readInt32)
+	info.Stats.PP = readField(fields, "Stats.PP", readUint16)
+
+	return info, fields.Err()
+}
+
+func (client *b20121223) ReadPresence(reader io.Reader) (*UserInfo, error) {
+	fields := newFieldReader(reader, "UserInfo")
+	info := &UserInfo{
+		Presence: &UserPresence{},
+		Stats:    &UserStats{},
+	}
+
+	info.Id = readField(fields, "Id", readInt32)
+	info.Name = readField(fields, "Name", readString)
+	timezone := readField(fields, "Presence.Timezone", readUint8)
+	info.Presence.Timezone = int8(timezone) - 24
+	info.Presence.CountryIndex = int8(readField(fields, "Presence.CountryIndex", readUint8))
+
+	// Permissions & mode are sent inside of the same byte
+	permissions := readField(fields, "Presence.Permissions", readUint8)
+	info.Presence.Permissions = permissions & 0x1F
+	info.Status = &UserStatus{Mode: permissions >> 5}
+
+	info.Presence.Longitude = readField(fields, "Presence.Longitude", readFloat32)
+	info.Presence.Latitude = readField(fields, "Presence.Latitude", readFloat32)
+	info.Stats.Rank = readField(fields, "Stats.Rank", readInt32)
+
+	return info, fields.Err()
+}
+
+func (client *b20121223) Clone() BanchoIO {
+	clone := client.clone()
+	clone.BanchoIO = clone
+	return clone
+}
+
+func (client *b20121223) clone() *b20121223 {
+	return &b20121223{client.b20120812.clone()}
+}
+
+func newB20121223() *b20121223 {
+	client := &b20121223{newB20120812()}
+	client.BanchoIO = client
+	client.version = 20121223
+
+	client.readers[BanchoUserPresence] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadPresence(reader)
+	}
+	client.readers[BanchoUserPresenceSingle] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[BanchoUserPresenceBundle] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readIntList16(reader)
+	}
+
+	client.supportedPackets = append(
+		client.supportedPackets,
+		BanchoUserPresence,
+		BanchoUserPresenceSingle,
+		BanchoUserPresenceBundle,
+	)
+
+	return client
+}
+
+func init() {
+	RegisterClient(20121223, newB20121223())
+}
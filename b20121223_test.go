@@ -0,0 +1,148 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func newTestUserInfo() UserInfo {
+	return UserInfo{
+		Id:       2,
+		Presence: &UserPresence{},
+		Status: &UserStatus{
+			Action:          StatusPlaying,
+			Text:            "Kenji Ninuma - DISCOTHEQUE",
+			BeatmapChecksum: "a5b99395a42bd55bc5eb1d2411cbdf8b",
+			Mods:            576,
+			Mode:            1,
+			BeatmapId:       75,
+		},
+		Stats: &UserStats{
+			Rank:      1,
+			Rscore:    5000000,
+			Tscore:    9000000,
+			Accuracy:  0.5,
+			Playcount: 120,
+			PP:        4000,
+		},
+	}
+}
+
+func TestB20121223UserStats(t *testing.T) {
+	client := newTestClient(t, 20121223)
+	info := newTestUserInfo()
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserStats(stream, info)
+	})
+	expectPacket(t, packet, BanchoHandleOsuUpdate, &info)
+}
+
+func TestB20121223UserQuit(t *testing.T) {
+	client := newTestClient(t, 20121223)
+	info := newTestUserInfo()
+	quit := UserQuit{Info: &info, QuitState: QuitStateGone}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserQuit(stream, quit)
+	})
+	expectPacket(t, packet, BanchoHandleOsuQuit, &quit)
+}
+
+func TestB20121223UserStatus(t *testing.T) {
+	client := newTestClient(t, 20121223)
+	status := *newTestUserInfo().Status
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuUserStatus(stream, status)
+	})
+	expectPacket(t, packet, OsuSendUserStatus, &status)
+}
+
+func TestB20121223UserPresence(t *testing.T) {
+	client := newTestClient(t, 20121223)
+	info := UserInfo{
+		Id:   2,
+		Name: "peppy",
+		Presence: &UserPresence{
+			Timezone:     -5,
+			CountryIndex: 14,
+			Permissions:  16,
+			Longitude:    115.86,
+			Latitude:     -31.95,
+		},
+		Status: &UserStatus{Mode: 3},
+		Stats:  &UserStats{Rank: 1},
+	}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserPresence(stream, info)
+	})
+	expectPacket(t, packet, BanchoUserPresence, &info)
+
+	// Irc users only have a presence
+	info.Presence.IsIrc = true
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserStats(stream, info)
+	})
+	info.Presence.IsIrc = false
+	expectPacket(t, packet, BanchoUserPresence, &info)
+}
+
+func TestB20121223UserPresenceBundle(t *testing.T) {
+	client := newTestClient(t, 20121223)
+	infos := []UserInfo{{Id: 2}, {Id: 3}, {Id: 1000}}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserPresenceSingle(stream, infos[0])
+	})
+	expectPacket(t, packet, BanchoUserPresenceSingle, int32(2))
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserPresenceBundle(stream, infos)
+	})
+	expectPacket(t, packet, BanchoUserPresenceBundle, []int32{2, 3, 1000})
+}
+
+func TestB20121223SpectateFrames(t *testing.T) {
+	client := newTestClient(t, 20121223)
+	frame := newTestScoreFrame()
+	frame.TagByte = 1
+
+	bundle := newTestFrameBundle()
+	bundle.Extra = 7
+	bundle.Frames[0].ButtonState = ButtonStateLeft2 | ButtonStateSmoke
+	bundle.Frame = &frame
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteSpectateFrames(stream, bundle)
+	})
+	expectPacket(t, packet, BanchoSpectateFrames, &bundle)
+}
+
+func TestB20121223WriteRawPacket(t *testing.T) {
+	client := newTestClient(t, 20121223)
+	payload := []byte{0x0b, 0x05, 'p', 'e', 'p', 'p', 'y'}
+
+	stream := bytes.NewBuffer([]byte{})
+	err := client.WriteRawPacket(stream, RawPacket{Id: BanchoSpectateFrames, Payload: payload})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The id is not converted, and the payload is sent uncompressed
+	expected := append([]byte{byte(BanchoSpectateFrames), 0, 0, byte(len(payload)), 0, 0, 0}, payload...)
+	if !bytes.Equal(stream.Bytes(), expected) {
+		t.Fatalf("expected %v, got %v", expected, stream.Bytes())
+	}
+
+	packet, err := client.ReadRawPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Id != BanchoSpectateFrames || !bytes.Equal(packet.Payload, payload) {
+		t.Fatalf("expected packet %d with %v, got %d with %v", BanchoSpectateFrames, payload, packet.Id, packet.Payload)
+	}
+}
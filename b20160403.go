@@ -0,0 +1,79 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+)
+
+// b20160403 adds ScoreV2 to score frames. The flag is always sent, and is
+// followed by the combo & bonus portion of the score when it's enabled.
+type b20160403 struct {
+	*b20121223
+}
+
+func (client *b20160403) WriteSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteFrameBundle(writer, bundle)
+	return client.BanchoIO.WritePacket(stream, BanchoSpectateFrames, writer.Bytes())
+}
+
+func (client *b20160403) WriteOsuSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteFrameBundle(writer, bundle)
+	return client.BanchoIO.WritePacket(stream, OsuSpectateFrames, writer.Bytes())
+}
+
+func (client *b20160403) WriteMatchScoreUpdate(stream io.Writer, frame ScoreFrame) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteScoreFrame(writer, frame)
+	return client.BanchoIO.WritePacket(stream, BanchoMatchScoreUpdate, writer.Bytes())
+}
+
+func (client *b20160403) WriteOsuMatchScoreUpdate(stream io.Writer, frame ScoreFrame) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteScoreFrame(writer, frame)
+	return client.BanchoIO.WritePacket(stream, OsuMatchScoreUpdate, writer.Bytes())
+}
+
+func (client *b20160403) WriteFrameBundle(writer io.Writer, bundle ReplayFrameBundle) error {
+	// The score frame of the parent doesn't contain the ScoreV2 flag
+	frame := bundle.Frame
+	bundle.Frame = nil
+	client.b20121223.WriteFrameBundle(writer, bundle)
+
+	if frame != nil {
+		client.WriteScoreFrame(writer, *frame)
+	}
+	return nil
+}
+
+func (client *b20160403) WriteScoreFrame(writer io.Writer, frame ScoreFrame) error {
+	return MarshalTo(writer, &frame, client.version)
+}
+
+func (client *b20160403) ReadScoreFrame(reader io.Reader) (*ScoreFrame, error) {
+	frame := &ScoreFrame{}
+	err := UnmarshalFrom(reader, frame, client.version)
+	return frame, err
+}
+
+func (client *b20160403) Clone() BanchoIO {
+	clone := client.clone()
+	clone.BanchoIO = clone
+	return clone
+}
+
+func (client *b20160403) clone() *b20160403 {
+	return &b20160403{client.b20121223.clone()}
+}
+
+func newB20160403() *b20160403 {
+	client := &b20160403{newB20121223()}
+	client.BanchoIO = client
+	client.version = 20160403
+	return client
+}
+
+func init() {
+	RegisterClient(20160403, newB20160403())
+}
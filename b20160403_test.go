@@ -0,0 +1,111 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestB20160403MatchScoreUpdate(t *testing.T) {
+	client := newTestClient(t, 20160403)
+	frame := newTestScoreFrame()
+	frame.TagByte = 2
+
+	// The ScoreV2 flag is sent for every frame
+	payload := bytes.NewBuffer([]byte{})
+	newB20160403().WriteScoreFrame(payload, frame)
+	if payload.Len() != 29 {
+		t.Fatalf("expected a score frame of 29 bytes, got %d", payload.Len())
+	}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMatchScoreUpdate(stream, frame)
+	})
+	expectPacket(t, packet, BanchoMatchScoreUpdate, &frame)
+
+	frame.ScoreV2 = true
+	frame.ComboPortion = 0.75
+	frame.BonusPortion = 0.125
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuMatchScoreUpdate(stream, frame)
+	})
+	expectPacket(t, packet, OsuMatchScoreUpdate, &frame)
+}
+
+func TestB20160403SpectateFrames(t *testing.T) {
+	client := newTestClient(t, 20160403)
+	frame := newTestScoreFrame()
+	frame.ScoreV2 = true
+	frame.ComboPortion = 0.5
+	frame.BonusPortion = 0.25
+
+	bundle := newTestFrameBundle()
+	bundle.Frame = &frame
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteSpectateFrames(stream, bundle)
+	})
+	expectPacket(t, packet, BanchoSpectateFrames, &bundle)
+
+	frame.ScoreV2 = false
+	frame.ComboPortion = 0
+	frame.BonusPortion = 0
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuSpectateFrames(stream, bundle)
+	})
+	expectPacket(t, packet, OsuSpectateFrames, &bundle)
+}
+
+func TestB20160403ScoreFrameErrors(t *testing.T) {
+	client := newTestClient(t, 20160403)
+	frame := newTestScoreFrame()
+	frame.ScoreV2 = true
+
+	payload := bytes.NewBuffer([]byte{})
+	newB20160403().WriteScoreFrame(payload, frame)
+
+	// The portions are cut off after the flag
+	_, err := client.DecodePayload(BanchoMatchScoreUpdate, payload.Bytes()[:29])
+
+	var fieldError *FieldError
+	if !errors.As(err, &fieldError) || fieldError.Field != "ScoreFrame.ComboPortion" {
+		t.Fatalf("expected error for ScoreFrame.ComboPortion, got %v", err)
+	}
+	if !errors.Is(err, ErrShortPacket) {
+		t.Fatalf("expected ErrShortPacket, got %v", err)
+	}
+}
+
+func TestB20160403ScoreFrameChecksum(t *testing.T) {
+	client := newTestClient(t, 20160403)
+	frame := newTestScoreFrame()
+	frame.Pass = true
+	frame.ScoreV2 = true
+	frame.ComboPortion = 0.75
+	frame.BonusPortion = 0.125
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMatchScoreUpdate(stream, frame)
+	})
+
+	// Pass is not sent, and the portions don't affect the checksum
+	decoded := *packet.Data.(*ScoreFrame)
+	decoded.Pass = true
+	if decoded != frame {
+		t.Fatalf("expected %#v, got %#v", frame, decoded)
+	}
+
+	checksum, err := client.ScoreFrameChecksum(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := newTestScoreFrame()
+	legacy.Pass = true
+	expected, _ := newTestClient(t, 323).ScoreFrameChecksum(legacy)
+	if checksum != expected {
+		t.Fatalf("expected checksum %s, got %s", expected, checksum)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 )
 
 // b282 is the initial implementation of the bancho protocol.
@@ -11,14 +13,20 @@ import (
 type b282 struct {
 	BanchoIO
 	supportedPackets []uint16
+	version          int
 	protocolVersion  int
 	slotSize         int
+	maxPacketSize    int
 	readers          ReaderRegistry
+
+	maxDecompressedSize int
+	maxCompressionRatio int
+	skipUnknownPackets  bool
 }
 
 func (client *b282) WritePacket(stream io.Writer, packetId uint16, data []byte) error {
 	// Convert packetId back for the client
-	packetId = client.ConvertOutputPacketId(packetId)
+	packetId = client.BanchoIO.ConvertOutputPacketId(packetId)
 	writer := bytes.NewBuffer([]byte{})
 
 	err := writeUint16(writer, packetId)
@@ -42,44 +50,45 @@ func (client *b282) WritePacket(stream io.Writer, packetId uint16, data []byte)
 }
 
 func (client *b282) ReadPacket(stream io.Reader) (packet *BanchoPacket, err error) {
-	packet = &BanchoPacket{}
-	packet.Id, err = readUint16(stream)
+	header, err := client.BanchoIO.ReadPacketHeader(stream)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert packet ID to a usable value
-	packet.Id = client.ConvertInputPacketId(packet.Id)
+	implemented := client.ImplementsPacket(header.Id)
 
-	if !client.ImplementsPacket(packet.Id) {
-		return nil, fmt.Errorf("packet '%d' not implemented", packet.Id)
+	if !implemented && !client.skipUnknownPackets {
+		return nil, &ErrUnsupportedPacket{Id: header.Id, Version: client.version}
 	}
 
-	length, err := readInt32(stream)
+	data, err := readPacketData(stream, header.Length, client.maxPacketSize)
 	if err != nil {
 		return nil, err
 	}
 
-	compressedData := make([]byte, length)
-	n, err := stream.Read(compressedData)
-	if err != nil {
-		return nil, err
+	if header.Compressed {
+		data, err = decompressData(data, decompressionLimit(client, len(data)))
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if n != int(length) {
-		return nil, fmt.Errorf("expected %d bytes, got %d", length, n)
-	}
+	packet = &BanchoPacket{Id: header.Id}
 
-	data, err := decompressData(compressedData)
-	if err != nil {
-		return nil, err
+	if !implemented {
+		unsupported := &ErrUnsupportedPacket{Id: header.Id, Version: client.version}
+		packet.Data = &UnknownPacket{Payload: data, Err: unsupported}
+		return packet, nil
 	}
 
 	reader, ok := client.readers[packet.Id]
-	packet.Data = nil
 
 	if ok {
-		packet.Data, err = reader(client, bytes.NewReader(data))
+		packet.Data, err = decodePacket(client.BanchoIO, reader, packet.Id, bytes.NewReader(data))
+		if err != nil && client.skipUnknownPackets {
+			packet.Data = &UnknownPacket{Payload: data, Err: err}
+			return packet, nil
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -88,6 +97,63 @@ func (client *b282) ReadPacket(stream io.Reader) (packet *BanchoPacket, err erro
 	return packet, nil
 }
 
+func (client *b282) ReadRawPacket(stream io.Reader) (packet *RawPacket, err error) {
+	header, err := client.BanchoIO.ReadPacketHeader(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readPacketData(stream, header.Length, client.maxPacketSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Compressed {
+		data, err = decompressData(data, decompressionLimit(client, len(data)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RawPacket{Id: header.Id, Payload: data}, nil
+}
+
+func (client *b282) WriteRawPacket(stream io.Writer, packet RawPacket) error {
+	if !client.BanchoIO.ImplementsPacket(packet.Id) {
+		return nil
+	}
+	return client.BanchoIO.WritePacket(stream, packet.Id, packet.Payload)
+}
+
+func (client *b282) DecodePayload(packetId uint16, payload []byte) (any, error) {
+	if !client.BanchoIO.ImplementsPacket(packetId) {
+		return nil, &ErrUnsupportedPacket{Id: packetId, Version: client.version}
+	}
+
+	reader, ok := client.BanchoIO.GetReaders()[packetId]
+	if !ok {
+		// Packet doesn't contain any data
+		return nil, nil
+	}
+
+	return decodePacket(client.BanchoIO, reader, packetId, bytes.NewReader(payload))
+}
+
+func (client *b282) ReadPacketHeader(stream io.Reader) (header PacketHeader, err error) {
+	header.Id, err = readUint16(stream)
+	if err != nil {
+		return header, err
+	}
+
+	// Convert packet ID to a usable value
+	header.Id = client.BanchoIO.ConvertInputPacketId(header.Id)
+
+	// Packet data is always compressed in this version
+	header.Compressed = true
+	header.Length, err = readInt32(stream)
+	return header, shortPacketError(err)
+}
+
 func (client *b282) SupportedPackets() []uint16 {
 	return client.supportedPackets
 }
@@ -101,6 +167,10 @@ func (client *b282) ImplementsPacket(packetId uint16) bool {
 	return false
 }
 
+func (client *b282) Version() int {
+	return client.version
+}
+
 func (client *b282) ProtocolVersion() int {
 	return client.protocolVersion
 }
@@ -117,6 +187,38 @@ func (client *b282) OverrideMatchSlotSize(amount int) {
 	client.slotSize = amount
 }
 
+func (client *b282) MaxPacketSize() int {
+	return client.maxPacketSize
+}
+
+func (client *b282) OverrideMaxPacketSize(size int) {
+	client.maxPacketSize = size
+}
+
+func (client *b282) MaxDecompressedSize() int {
+	return client.maxDecompressedSize
+}
+
+func (client *b282) OverrideMaxDecompressedSize(size int) {
+	client.maxDecompressedSize = size
+}
+
+func (client *b282) MaxCompressionRatio() int {
+	return client.maxCompressionRatio
+}
+
+func (client *b282) OverrideMaxCompressionRatio(ratio int) {
+	client.maxCompressionRatio = ratio
+}
+
+func (client *b282) SkipUnknownPackets() bool {
+	return client.skipUnknownPackets
+}
+
+func (client *b282) OverrideSkipUnknownPackets(skip bool) {
+	client.skipUnknownPackets = skip
+}
+
 func (client *b282) ConvertInputPacketId(packetId uint16) uint16 {
 	if packetId == 11 {
 		// "IrcJoin" packet
@@ -145,14 +247,42 @@ func (client *b282) ConvertOutputPacketId(packetId uint16) uint16 {
 	return packetId
 }
 
+func (client *b282) ScoreFrameChecksum(frame ScoreFrame) (string, error) {
+	// Score frames have not been implemented yet
+	return "", &ErrUnsupportedPacket{Id: OsuMatchScoreUpdate, Version: client.version}
+}
+
+func (client *b282) ConvertButtonState(state uint8) ButtonStateConversion {
+	return convertLegacyButtonState(state)
+}
+
 func (client *b282) GetReaders() ReaderRegistry {
 	return client.readers
 }
 
+func (client *b282) Clone() BanchoIO {
+	clone := client.clone()
+	clone.BanchoIO = clone
+	return clone
+}
+
+// clone copies the client configuration, without sharing any
+// state with the original. Used by the Clone method of every version.
+func (client *b282) clone() *b282 {
+	clone := *client
+	clone.readers = InheritReaders(client.readers)
+	clone.supportedPackets = slices.Clone(client.supportedPackets)
+	return &clone
+}
+
 func (client *b282) WriteLoginReply(stream io.Writer, reply int32) error {
 	writer := bytes.NewBuffer([]byte{})
 	writeInt32(writer, reply)
-	return client.WritePacket(stream, BanchoLoginReply, writer.Bytes())
+	return client.BanchoIO.WritePacket(stream, BanchoLoginReply, writer.Bytes())
+}
+
+func (client *b282) WriteCommandError(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, BanchoCommandError, []byte{})
 }
 
 func (client *b282) WriteMessage(stream io.Writer, message Message) error {
@@ -164,17 +294,17 @@ func (client *b282) WriteMessage(stream io.Writer, message Message) error {
 	writer := bytes.NewBuffer([]byte{})
 	writeString(writer, message.Sender)
 	writeString(writer, message.Content)
-	return client.WritePacket(stream, BanchoSendMessage, writer.Bytes())
+	return client.BanchoIO.WritePacket(stream, BanchoSendMessage, writer.Bytes())
 }
 
 func (client *b282) WritePing(stream io.Writer) error {
-	return client.WritePacket(stream, BanchoPing, []byte{})
+	return client.BanchoIO.WritePacket(stream, BanchoPing, []byte{})
 }
 
 func (client *b282) WriteIrcChangeUsername(stream io.Writer, oldName string, newName string) error {
 	writer := bytes.NewBuffer([]byte{})
 	writeString(writer, fmt.Sprintf("%s>>>>%s", oldName, newName))
-	return client.WritePacket(stream, BanchoHandleIrcChangeUsername, writer.Bytes())
+	return client.BanchoIO.WritePacket(stream, BanchoHandleIrcChangeUsername, writer.Bytes())
 }
 
 func (client *b282) WriteUserStats(stream io.Writer, info UserInfo) error {
@@ -182,11 +312,11 @@ func (client *b282) WriteUserStats(stream io.Writer, info UserInfo) error {
 
 	if info.Presence.IsIrc {
 		writeString(writer, info.Name)
-		return client.WritePacket(stream, BanchoHandleIrcJoin, writer.Bytes())
+		return client.BanchoIO.WritePacket(stream, BanchoHandleIrcJoin, writer.Bytes())
 	}
 
 	client.WriteStats(writer, info)
-	return client.WritePacket(stream, BanchoHandleOsuUpdate, writer.Bytes())
+	return client.BanchoIO.WritePacket(stream, BanchoHandleOsuUpdate, writer.Bytes())
 }
 
 func (client *b282) WriteUserQuit(stream io.Writer, quit UserQuit) error {
@@ -194,7 +324,7 @@ func (client *b282) WriteUserQuit(stream io.Writer, quit UserQuit) error {
 
 	if quit.Info.Presence.IsIrc && quit.QuitState != QuitStateIrcRemaining {
 		writeString(writer, quit.Info.Name)
-		return client.WritePacket(stream, BanchoHandleIrcQuit, writer.Bytes())
+		return client.BanchoIO.WritePacket(stream, BanchoHandleIrcQuit, writer.Bytes())
 	}
 
 	if quit.QuitState == QuitStateOsuRemaining {
@@ -202,29 +332,33 @@ func (client *b282) WriteUserQuit(stream io.Writer, quit UserQuit) error {
 	}
 
 	client.WriteStats(writer, *quit.Info)
-	return client.WritePacket(stream, BanchoHandleOsuQuit, writer.Bytes())
+	return client.BanchoIO.WritePacket(stream, BanchoHandleOsuQuit, writer.Bytes())
 }
 
 func (client *b282) WriteSpectatorJoined(stream io.Writer, userId int32) error {
 	writer := bytes.NewBuffer([]byte{})
 	writeInt32(writer, userId)
-	return client.WritePacket(stream, BanchoSpectatorJoined, writer.Bytes())
+	return client.BanchoIO.WritePacket(stream, BanchoSpectatorJoined, writer.Bytes())
 }
 
 func (client *b282) WriteSpectatorLeft(stream io.Writer, userId int32) error {
 	writer := bytes.NewBuffer([]byte{})
 	writeInt32(writer, userId)
-	return client.WritePacket(stream, BanchoSpectatorLeft, writer.Bytes())
+	return client.BanchoIO.WritePacket(stream, BanchoSpectatorLeft, writer.Bytes())
 }
 
 func (client *b282) WriteSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error {
 	writer := bytes.NewBuffer([]byte{})
+	client.WriteFrameBundle(writer, bundle)
+	return client.BanchoIO.WritePacket(stream, BanchoSpectateFrames, writer.Bytes())
+}
+
+func (client *b282) WriteFrameBundle(writer io.Writer, bundle ReplayFrameBundle) error {
 	writeUint16(writer, uint16(len(bundle.Frames)))
 
 	for _, frame := range bundle.Frames {
-		// Convert button state
-		leftMouse := ButtonStateLeft1&frame.ButtonState > 0 || ButtonStateLeft2&frame.ButtonState > 0
-		rightMouse := ButtonStateRight1&frame.ButtonState > 0 || ButtonStateRight2&frame.ButtonState > 0
+		// Only the mouse buttons are sent, see ConvertButtonState
+		leftMouse, rightMouse := legacyButtons(frame.ButtonState)
 
 		writeBoolean(writer, leftMouse)
 		writeBoolean(writer, rightMouse)
@@ -234,29 +368,23 @@ func (client *b282) WriteSpectateFrames(stream io.Writer, bundle ReplayFrameBund
 	}
 
 	writeUint8(writer, bundle.Action)
-	return client.WritePacket(stream, BanchoSpectateFrames, writer.Bytes())
+	return nil
 }
 
 func (client *b282) WriteVersionUpdate(stream io.Writer) error {
-	return client.WritePacket(stream, BanchoVersionUpdate, []byte{})
+	return client.BanchoIO.WritePacket(stream, BanchoVersionUpdate, []byte{})
 }
 
 func (client *b282) WriteSpectatorCantSpectate(stream io.Writer, userId int32) error {
 	writer := bytes.NewBuffer([]byte{})
 	writeInt32(writer, userId)
-	return client.WritePacket(stream, BanchoSpectatorCantSpectate, writer.Bytes())
+	return client.BanchoIO.WritePacket(stream, BanchoSpectatorCantSpectate, writer.Bytes())
 }
 
 func (client *b282) WriteStatus(writer io.Writer, status *UserStatus) error {
-	// Convert action enum
-	action := status.Action
-
-	if status.UpdateStats {
-		// This will make the client update the user's stats
-		// It will not be present in later versions
-		action = StatusStatsUpdate
-	}
-
+	// This will make the client update the user's stats, if requested
+	// It will not be present in later versions
+	action := status.LegacyAction()
 	writeUint8(writer, action)
 
 	if action != StatusUnknown {
@@ -285,16 +413,16 @@ func (client *b282) WriteStats(writer io.Writer, info UserInfo) error {
 
 // Redirect UserPresence packets to UserStats
 func (client *b282) WriteUserPresence(stream io.Writer, info UserInfo) error {
-	return client.WriteUserStats(stream, info)
+	return client.BanchoIO.WriteUserStats(stream, info)
 }
 
 func (client *b282) WriteUserPresenceSingle(stream io.Writer, info UserInfo) error {
-	return client.WriteUserPresence(stream, info)
+	return client.BanchoIO.WriteUserPresence(stream, info)
 }
 
 func (client *b282) WriteUserPresenceBundle(stream io.Writer, infos []UserInfo) error {
 	for _, info := range infos {
-		err := client.WriteUserPresence(stream, info)
+		err := client.BanchoIO.WriteUserPresence(stream, info)
 		if err != nil {
 			return err
 		}
@@ -302,24 +430,73 @@ func (client *b282) WriteUserPresenceBundle(stream io.Writer, infos []UserInfo)
 	return nil
 }
 
+func (client *b282) WriteOsuUserStatus(stream io.Writer, status UserStatus) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteStatus(writer, &status)
+	return client.BanchoIO.WritePacket(stream, OsuSendUserStatus, writer.Bytes())
+}
+
+func (client *b282) WriteOsuMessage(stream io.Writer, message Message) error {
+	if message.Target != "#osu" {
+		// Private messages & channels have not been implemented yet
+		return nil
+	}
+
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, message.Content)
+	return client.BanchoIO.WritePacket(stream, OsuSendIrcMessage, writer.Bytes())
+}
+
+func (client *b282) WriteOsuExit(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuExit, []byte{})
+}
+
+func (client *b282) WriteOsuStatusUpdateRequest(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuRequestStatusUpdate, []byte{})
+}
+
+func (client *b282) WriteOsuPong(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuPong, []byte{})
+}
+
+func (client *b282) WriteOsuStartSpectating(stream io.Writer, userId int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeInt32(writer, userId)
+	return client.BanchoIO.WritePacket(stream, OsuStartSpectating, writer.Bytes())
+}
+
+func (client *b282) WriteOsuStopSpectating(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuStopSpectating, []byte{})
+}
+
+func (client *b282) WriteOsuSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteFrameBundle(writer, bundle)
+	return client.BanchoIO.WritePacket(stream, OsuSpectateFrames, writer.Bytes())
+}
+
+func (client *b282) WriteOsuErrorReport(stream io.Writer, report string) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, report)
+	return client.BanchoIO.WritePacket(stream, OsuErrorReport, writer.Bytes())
+}
+
+func (client *b282) WriteOsuCantSpectate(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuCantSpectate, []byte{})
+}
+
 func (client *b282) ReadStatus(reader io.Reader) (*UserStatus, error) {
-	var err error
-	errors := NewErrorCollection()
+	fields := newFieldReader(reader, "UserStatus")
 	status := &UserStatus{}
-	status.Action, err = readUint8(reader)
-	errors.Add(err)
+	status.Action = readField(fields, "Action", readUint8)
 
 	if status.Action != StatusUnknown {
-		status.Text, err = readString(reader)
-		errors.Add(err)
-		status.BeatmapChecksum, err = readString(reader)
-		errors.Add(err)
-		mods, err := readUint16(reader)
-		errors.Add(err)
-		status.Mods = uint32(mods)
+		status.Text = readField(fields, "Text", readString)
+		status.BeatmapChecksum = readField(fields, "BeatmapChecksum", readString)
+		status.Mods = uint32(readField(fields, "Mods", readUint16))
 	}
 
-	return status, errors.Next()
+	return status, fields.Err()
 }
 
 func (client *b282) ReadMessage(reader io.Reader) (*Message, error) {
@@ -337,79 +514,137 @@ func (client *b282) ReadMessage(reader io.Reader) (*Message, error) {
 	return message, nil
 }
 
-func (client *b282) ReadFrameBundle(reader io.Reader) (*ReplayFrameBundle, error) {
-	count, err := readUint16(reader)
-	if err != nil {
-		return nil, err
+func (client *b282) ReadStats(reader io.Reader) (*UserInfo, error) {
+	fields := newFieldReader(reader, "UserInfo")
+	info := &UserInfo{
+		Presence: &UserPresence{},
+		Stats:    &UserStats{},
 	}
 
-	frames := make([]*ReplayFrame, count)
-	for i := 0; i < int(count); i++ {
-		frame, err := client.ReadReplayFrame(reader)
-		if err != nil {
-			return nil, err
-		}
-		frames[i] = frame
-	}
+	info.Id = readField(fields, "Id", readInt32)
+	info.Name = readField(fields, "Name", readString)
+	info.Stats.Rscore = readField(fields, "Stats.Rscore", readUint64)
+	info.Stats.Accuracy = readField(fields, "Stats.Accuracy", readFloat64)
+	info.Stats.Playcount = readField(fields, "Stats.Playcount", readInt32)
+	info.Stats.Tscore = readField(fields, "Stats.Tscore", readUint64)
+	info.Stats.Rank = readField(fields, "Stats.Rank", readInt32)
+	readField(fields, "AvatarFilename", readString)
+	info.Status = readField(fields, "Status", client.ReadStatus)
+	timezone := readField(fields, "Presence.Timezone", readUint8)
+	info.Presence.Timezone = int8(timezone) - 24
+	location := readField(fields, "Presence.Location", readString)
 
-	action, err := readUint8(reader)
-	if err != nil {
-		return nil, err
-	}
+	// Location is formatted as "<Country> / <City>"
+	country, city, _ := strings.Cut(location, " / ")
+	info.Presence.CountryIndex = GetCountryIndexFromName(country)
+	info.Presence.City = city
 
-	return &ReplayFrameBundle{Frames: frames, Action: action}, nil
+	return info, fields.Err()
 }
 
-func (client *b282) ReadReplayFrame(reader io.Reader) (*ReplayFrame, error) {
-	var err error
-	errors := NewErrorCollection()
-	frame := &ReplayFrame{}
-	mouseLeft, err := readBoolean(reader)
-	errors.Add(err)
-	mouseRight, err := readBoolean(reader)
-	errors.Add(err)
-	frame.MouseX, err = readFloat32(reader)
-	errors.Add(err)
-	frame.MouseY, err = readFloat32(reader)
-	errors.Add(err)
-	frame.Time, err = readInt32(reader)
-	errors.Add(err)
+func (client *b282) ReadFrameBundle(reader io.Reader) (*ReplayFrameBundle, error) {
+	fields := newFieldReader(reader, "ReplayFrameBundle")
+	bundle := &ReplayFrameBundle{}
+	count := readField(fields, "FrameCount", readUint16)
+	bundle.Frames = readList(fields, "Frames", int(count), client.ReadReplayFrame)
+	bundle.Action = readField(fields, "Action", readUint8)
 
-	frame.ButtonState = 0
+	return bundle, fields.Err()
+}
 
-	if mouseLeft {
-		frame.ButtonState |= ButtonStateLeft1
-	}
-	if mouseRight {
-		frame.ButtonState |= ButtonStateRight1
-	}
+func (client *b282) ReadReplayFrame(reader io.Reader) (*ReplayFrame, error) {
+	fields := newFieldReader(reader, "ReplayFrame")
+	frame := &ReplayFrame{}
+	mouseLeft := readField(fields, "MouseLeft", readBoolean)
+	mouseRight := readField(fields, "MouseRight", readBoolean)
+	frame.MouseX = readField(fields, "MouseX", readFloat32)
+	frame.MouseY = readField(fields, "MouseY", readFloat32)
+	frame.Time = readField(fields, "Time", readInt32)
+	frame.ButtonState = buttonStateFromLegacy(mouseLeft, mouseRight)
 
-	return frame, errors.Next()
+	return frame, fields.Err()
 }
 
-func init() {
+func newB282() *b282 {
 	client := &b282{
+		version:         282,
 		slotSize:        8,
 		protocolVersion: 0,
+		maxPacketSize:   defaultMaxPacketSize,
 		readers:         make(ReaderRegistry),
+
+		maxDecompressedSize: defaultMaxDecompressedSize,
+		maxCompressionRatio: defaultMaxCompressionRatio,
 	}
+	client.BanchoIO = client
 
 	client.readers[OsuSendUserStatus] = func(c BanchoIO, reader io.Reader) (any, error) {
-		return c.(*b282).ReadStatus(reader)
+		return c.ReadStatus(reader)
 	}
 	client.readers[OsuSendIrcMessage] = func(c BanchoIO, reader io.Reader) (any, error) {
-		return c.(*b282).ReadMessage(reader)
+		return c.ReadMessage(reader)
 	}
 	client.readers[OsuStartSpectating] = func(c BanchoIO, reader io.Reader) (any, error) {
 		return readUint32(reader)
 	}
 	client.readers[OsuSpectateFrames] = func(c BanchoIO, reader io.Reader) (any, error) {
-		return c.(*b282).ReadFrameBundle(reader)
+		return c.ReadFrameBundle(reader)
 	}
 	client.readers[OsuErrorReport] = func(c BanchoIO, reader io.Reader) (any, error) {
 		return readString(reader)
 	}
 
+	client.readers[BanchoLoginReply] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[BanchoSendMessage] = func(c BanchoIO, reader io.Reader) (any, error) {
+		fields := newFieldReader(reader, "Message")
+		message := &Message{Target: "#osu"}
+		message.Sender = readField(fields, "Sender", readString)
+		message.Content = readField(fields, "Content", readString)
+		return message, fields.Err()
+	}
+	client.readers[BanchoHandleIrcChangeUsername] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readString(reader)
+	}
+	client.readers[BanchoHandleIrcJoin] = func(c BanchoIO, reader io.Reader) (any, error) {
+		name, err := readString(reader)
+		if err != nil {
+			return nil, err
+		}
+		return &UserInfo{Name: name, Presence: &UserPresence{IsIrc: true}}, nil
+	}
+	client.readers[BanchoHandleIrcQuit] = func(c BanchoIO, reader io.Reader) (any, error) {
+		name, err := readString(reader)
+		if err != nil {
+			return nil, err
+		}
+		info := &UserInfo{Name: name, Presence: &UserPresence{IsIrc: true}}
+		return &UserQuit{Info: info, QuitState: QuitStateGone}, nil
+	}
+	client.readers[BanchoHandleOsuUpdate] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadStats(reader)
+	}
+	client.readers[BanchoHandleOsuQuit] = func(c BanchoIO, reader io.Reader) (any, error) {
+		info, err := c.ReadStats(reader)
+		if err != nil {
+			return nil, err
+		}
+		return &UserQuit{Info: info, QuitState: QuitStateGone}, nil
+	}
+	client.readers[BanchoSpectatorJoined] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[BanchoSpectatorLeft] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[BanchoSpectateFrames] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadFrameBundle(reader)
+	}
+	client.readers[BanchoSpectatorCantSpectate] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+
 	client.supportedPackets = []uint16{
 		OsuSendUserStatus,
 		OsuSendIrcMessage,
@@ -434,10 +669,14 @@ func init() {
 		OsuErrorReport,
 		OsuCantSpectate,
 		BanchoSpectatorCantSpectate,
+		BanchoHandleIrcJoin,
 	}
 
-	clients[282] = client
-	clients[290] = client
+	return client
+}
+
+func init() {
+	RegisterClient(282, newB282())
 }
 
 /* Unsupported Packets */
@@ -488,3 +727,35 @@ func (client *b282) WriteAccountRestricted(stream io.Writer) error
 func (client *b282) WriteRTX(stream io.Writer, message string) error                      { return nil }
 func (client *b282) WriteMatchAbort(stream io.Writer) error                               { return nil }
 func (client *b282) WriteSwitchTournamentServer(stream io.Writer, ip string) error        { return nil }
+
+func (client *b282) WriteOsuPrivateMessage(stream io.Writer, message Message) error    { return nil }
+func (client *b282) WriteOsuChannelJoin(stream io.Writer, channel string) error        { return nil }
+func (client *b282) WriteOsuChannelLeave(stream io.Writer, channel string) error       { return nil }
+func (client *b282) WriteOsuLobbyPart(stream io.Writer) error                          { return nil }
+func (client *b282) WriteOsuLobbyJoin(stream io.Writer) error                          { return nil }
+func (client *b282) WriteOsuMatchCreate(stream io.Writer, match Match) error           { return nil }
+func (client *b282) WriteOsuMatchJoin(stream io.Writer, join MatchJoin) error          { return nil }
+func (client *b282) WriteOsuMatchPart(stream io.Writer) error                          { return nil }
+func (client *b282) WriteOsuMatchChangeSlot(stream io.Writer, slotId int32) error      { return nil }
+func (client *b282) WriteOsuMatchReady(stream io.Writer) error                         { return nil }
+func (client *b282) WriteOsuMatchLock(stream io.Writer, slotId int32) error            { return nil }
+func (client *b282) WriteOsuMatchChangeSettings(stream io.Writer, match Match) error   { return nil }
+func (client *b282) WriteOsuMatchStart(stream io.Writer) error                         { return nil }
+func (client *b282) WriteOsuMatchScoreUpdate(stream io.Writer, frame ScoreFrame) error { return nil }
+func (client *b282) WriteOsuMatchComplete(stream io.Writer) error                      { return nil }
+
+func (client *b282) ReadMatch(reader io.Reader) (*Match, error) {
+	return nil, &ErrUnsupportedPacket{Id: BanchoMatchUpdate, Version: client.version}
+}
+func (client *b282) ReadMatchJoin(reader io.Reader) (*MatchJoin, error) {
+	return nil, &ErrUnsupportedPacket{Id: OsuMatchJoin, Version: client.version}
+}
+func (client *b282) ReadChannel(reader io.Reader) (*Channel, error) {
+	return nil, &ErrUnsupportedPacket{Id: BanchoChannelAvailable, Version: client.version}
+}
+func (client *b282) ReadScoreFrame(reader io.Reader) (*ScoreFrame, error) {
+	return nil, &ErrUnsupportedPacket{Id: BanchoMatchScoreUpdate, Version: client.version}
+}
+func (client *b282) ReadPresence(reader io.Reader) (*UserInfo, error) {
+	return nil, &ErrUnsupportedPacket{Id: BanchoUserPresence, Version: client.version}
+}
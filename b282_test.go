@@ -0,0 +1,214 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestB282UserStats(t *testing.T) {
+	client := newTestClient(t, 282)
+	info := UserInfo{
+		Id:   2,
+		Name: "peppy",
+		Presence: &UserPresence{
+			Timezone:     1,
+			CountryIndex: 14,
+			City:         "Perth",
+		},
+		Status: &UserStatus{
+			Action:          StatusPlaying,
+			Text:            "Kenji Ninuma - DISCOTHEQUE",
+			BeatmapChecksum: "a5b99395a42bd55bc5eb1d2411cbdf8b",
+			Mods:            64,
+		},
+		Stats: &UserStats{
+			Rank:      1,
+			Rscore:    5000000,
+			Tscore:    9000000,
+			Accuracy:  0.987,
+			Playcount: 120,
+		},
+	}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserStats(stream, info)
+	})
+	expectPacket(t, packet, BanchoHandleOsuUpdate, &info)
+}
+
+func TestB282UserQuit(t *testing.T) {
+	client := newTestClient(t, 282)
+	info := &UserInfo{
+		Id:       3,
+		Name:     "Test",
+		Presence: &UserPresence{},
+		Status:   &UserStatus{Action: StatusUnknown},
+		Stats:    &UserStats{},
+	}
+	quit := UserQuit{Info: info, QuitState: QuitStateGone}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserQuit(stream, quit)
+	})
+	expectPacket(t, packet, BanchoHandleOsuQuit, &quit)
+}
+
+func TestB282Message(t *testing.T) {
+	client := newTestClient(t, 282)
+	message := Message{Sender: "peppy", Content: "hello", Target: "#osu"}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMessage(stream, message)
+	})
+	expectPacket(t, packet, BanchoSendMessage, &message)
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuMessage(stream, message)
+	})
+	expectPacket(t, packet, OsuSendIrcMessage, &Message{Content: "hello", Target: "#osu"})
+}
+
+func TestB282UserStatus(t *testing.T) {
+	client := newTestClient(t, 282)
+	status := UserStatus{
+		Action:          StatusEditing,
+		Text:            "Editing",
+		BeatmapChecksum: "a5b99395a42bd55bc5eb1d2411cbdf8b",
+		Mods:            8,
+	}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuUserStatus(stream, status)
+	})
+	expectPacket(t, packet, OsuSendUserStatus, &status)
+}
+
+func TestB282SpectateFrames(t *testing.T) {
+	client := newTestClient(t, 282)
+	bundle := ReplayFrameBundle{
+		Action: 1,
+		Frames: []*ReplayFrame{
+			{ButtonState: ButtonStateLeft1, MouseX: 256, MouseY: 192, Time: 1000},
+			{ButtonState: ButtonStateRight1, MouseX: 128, MouseY: 96, Time: 1016},
+		},
+	}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteSpectateFrames(stream, bundle)
+	})
+	expectPacket(t, packet, BanchoSpectateFrames, &bundle)
+}
+
+func TestB282IrcUser(t *testing.T) {
+	client := newTestClient(t, 282)
+	info := UserInfo{Name: "irc_user", Presence: &UserPresence{IsIrc: true}}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteUserStats(stream, info)
+	})
+	expectPacket(t, packet, BanchoHandleIrcJoin, &info)
+}
+
+func TestB282UnsupportedReaders(t *testing.T) {
+	client := newTestClient(t, 282)
+	readers := map[string]func(io.Reader) (any, error){
+		"ReadChannel":    func(r io.Reader) (any, error) { return client.ReadChannel(r) },
+		"ReadScoreFrame": func(r io.Reader) (any, error) { return client.ReadScoreFrame(r) },
+		"ReadPresence":   func(r io.Reader) (any, error) { return client.ReadPresence(r) },
+	}
+
+	for name, read := range readers {
+		_, err := read(bytes.NewReader(nil))
+
+		var unsupported *ErrUnsupportedPacket
+		if !errors.As(err, &unsupported) {
+			t.Errorf("%s: expected ErrUnsupportedPacket, got %v", name, err)
+		}
+	}
+}
+
+func TestB282FrameBundleErrors(t *testing.T) {
+	client := newTestClient(t, 282)
+	bundle := newTestFrameBundle()
+
+	stream := bytes.NewBuffer([]byte{})
+	client.WriteSpectateFrames(stream, bundle)
+
+	packet, err := client.ReadRawPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Cut off the data in the middle of the second frame
+	_, err = client.DecodePayload(BanchoSpectateFrames, packet.Payload[:20])
+
+	var fieldError *FieldError
+	if !errors.As(err, &fieldError) || fieldError.Field != "ReplayFrameBundle.Frames[1]" {
+		t.Fatalf("expected error for ReplayFrameBundle.Frames[1], got %v", err)
+	}
+	if !errors.Is(err, ErrShortPacket) {
+		t.Fatalf("expected ErrShortPacket, got %v", err)
+	}
+}
+
+func TestB282WriteRawPacket(t *testing.T) {
+	tests := []struct {
+		version  int
+		packetId uint16
+		wireId   uint16
+	}{
+		{282, BanchoSendMessage, 7},
+		{282, BanchoSpectateFrames, 16},
+		{282, BanchoHandleIrcJoin, 11},
+		{294, OsuChannelJoin, 64},
+		{323, BanchoSpectateFrames, 16},
+	}
+	payload := []byte{0x0b, 0x05, 'p', 'e', 'p', 'p', 'y'}
+
+	for _, test := range tests {
+		client := newTestClient(t, test.version)
+		stream := bytes.NewBuffer([]byte{})
+
+		err := client.WriteRawPacket(stream, RawPacket{Id: test.packetId, Payload: payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The header contains the converted id, followed by the length of the gzip data
+		data := stream.Bytes()
+		wireId := uint16(data[0]) | uint16(data[1])<<8
+		if wireId != test.wireId {
+			t.Errorf("b%d: expected wire id %d for packet %d, got %d", test.version, test.wireId, test.packetId, wireId)
+		}
+
+		length := int(data[2]) | int(data[3])<<8 | int(data[4])<<16 | int(data[5])<<24
+		if length != len(data)-6 {
+			t.Fatalf("b%d: expected length %d, got %d", test.version, len(data)-6, length)
+		}
+
+		decompressed, err := decompressData(data[6:], defaultMaxDecompressedSize)
+		if err != nil {
+			t.Fatalf("b%d: %v", test.version, err)
+		}
+		if !bytes.Equal(decompressed, payload) {
+			t.Fatalf("b%d: expected payload %v, got %v", test.version, payload, decompressed)
+		}
+
+		packet, err := client.ReadRawPacket(stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packet.Id != test.packetId {
+			t.Fatalf("b%d: expected packet %d, got %d", test.version, test.packetId, packet.Id)
+		}
+	}
+
+	// Packets that are not implemented are not written
+	stream := bytes.NewBuffer([]byte{})
+	newTestClient(t, 282).WriteRawPacket(stream, RawPacket{Id: OsuChannelJoin, Payload: payload})
+	if stream.Len() > 0 {
+		t.Fatalf("expected no data for an unsupported packet, got %v", stream.Bytes())
+	}
+}
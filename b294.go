@@ -0,0 +1,150 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+)
+
+// b294 adds private messages and support for multiple chat channels.
+// Messages now carry their target, instead of always going to #osu.
+type b294 struct {
+	*b282
+}
+
+func (client *b294) WriteMessage(stream io.Writer, message Message) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, message.Sender)
+	writeString(writer, message.Content)
+	writeString(writer, message.Target)
+	return client.BanchoIO.WritePacket(stream, BanchoSendMessage, writer.Bytes())
+}
+
+func (client *b294) WriteChannelJoinSuccess(stream io.Writer, channel string) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, channel)
+	return client.BanchoIO.WritePacket(stream, BanchoChannelJoinSuccess, writer.Bytes())
+}
+
+func (client *b294) WriteChannelRevoked(stream io.Writer, channel string) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, channel)
+	return client.BanchoIO.WritePacket(stream, BanchoChannelRevoked, writer.Bytes())
+}
+
+func (client *b294) WriteChannelAvailable(stream io.Writer, channel Channel) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteChannel(writer, channel)
+	return client.BanchoIO.WritePacket(stream, BanchoChannelAvailable, writer.Bytes())
+}
+
+func (client *b294) WriteChannelAvailableAutojoin(stream io.Writer, channel Channel) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteChannel(writer, channel)
+	return client.BanchoIO.WritePacket(stream, BanchoChannelAvailableAutojoin, writer.Bytes())
+}
+
+func (client *b294) WriteChannel(writer io.Writer, channel Channel) error {
+	return MarshalTo(writer, &channel, client.version)
+}
+
+func (client *b294) WriteOsuMessage(stream io.Writer, message Message) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, message.Sender)
+	writeString(writer, message.Content)
+	writeString(writer, message.Target)
+	return client.BanchoIO.WritePacket(stream, OsuSendIrcMessage, writer.Bytes())
+}
+
+func (client *b294) WriteOsuPrivateMessage(stream io.Writer, message Message) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, message.Sender)
+	writeString(writer, message.Content)
+	writeString(writer, message.Target)
+	return client.BanchoIO.WritePacket(stream, OsuSendIrcMessagePrivate, writer.Bytes())
+}
+
+func (client *b294) WriteOsuChannelJoin(stream io.Writer, channel string) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, channel)
+	return client.BanchoIO.WritePacket(stream, OsuChannelJoin, writer.Bytes())
+}
+
+func (client *b294) WriteOsuChannelLeave(stream io.Writer, channel string) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeString(writer, channel)
+	return client.BanchoIO.WritePacket(stream, OsuChannelLeave, writer.Bytes())
+}
+
+func (client *b294) ReadMessage(reader io.Reader) (*Message, error) {
+	fields := newFieldReader(reader, "Message")
+	message := &Message{}
+	message.Sender = readField(fields, "Sender", readString)
+	message.Content = readField(fields, "Content", readString)
+	message.Target = readField(fields, "Target", readString)
+
+	return message, fields.Err()
+}
+
+func (client *b294) ReadChannel(reader io.Reader) (*Channel, error) {
+	channel := &Channel{}
+	err := UnmarshalFrom(reader, channel, client.version)
+	return channel, err
+}
+
+func (client *b294) Clone() BanchoIO {
+	clone := client.clone()
+	clone.BanchoIO = clone
+	return clone
+}
+
+func (client *b294) clone() *b294 {
+	return &b294{client.b282.clone()}
+}
+
+func newB294() *b294 {
+	client := &b294{newB282()}
+	client.BanchoIO = client
+	client.version = 294
+
+	client.readers[OsuSendIrcMessagePrivate] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMessage(reader)
+	}
+	client.readers[OsuChannelJoin] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readString(reader)
+	}
+	client.readers[OsuChannelLeave] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readString(reader)
+	}
+	client.readers[BanchoSendMessage] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMessage(reader)
+	}
+	client.readers[BanchoChannelJoinSuccess] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readString(reader)
+	}
+	client.readers[BanchoChannelAvailable] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadChannel(reader)
+	}
+	client.readers[BanchoChannelRevoked] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readString(reader)
+	}
+	client.readers[BanchoChannelAvailableAutojoin] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadChannel(reader)
+	}
+
+	client.supportedPackets = append(
+		client.supportedPackets,
+		OsuSendIrcMessagePrivate,
+		OsuChannelJoin,
+		BanchoChannelJoinSuccess,
+		BanchoChannelAvailable,
+		BanchoChannelRevoked,
+		BanchoChannelAvailableAutojoin,
+		OsuChannelLeave,
+	)
+
+	return client
+}
+
+func init() {
+	RegisterClient(294, newB294())
+}
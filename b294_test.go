@@ -0,0 +1,56 @@
+package chio
+
+import (
+	"io"
+	"testing"
+)
+
+func TestB294Message(t *testing.T) {
+	client := newTestClient(t, 294)
+	message := Message{Sender: "peppy", Content: "hello", Target: "BanchoBot"}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMessage(stream, message)
+	})
+	expectPacket(t, packet, BanchoSendMessage, &message)
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuPrivateMessage(stream, message)
+	})
+	expectPacket(t, packet, OsuSendIrcMessagePrivate, &message)
+}
+
+func TestB294Channels(t *testing.T) {
+	client := newTestClient(t, 294)
+	channel := Channel{Name: "#lobby", Topic: "Multiplayer lobby", UserCount: 12}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteChannelAvailable(stream, channel)
+	})
+	expectPacket(t, packet, BanchoChannelAvailable, &channel)
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteChannelAvailableAutojoin(stream, channel)
+	})
+	expectPacket(t, packet, BanchoChannelAvailableAutojoin, &channel)
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteChannelJoinSuccess(stream, "#osu")
+	})
+	expectPacket(t, packet, BanchoChannelJoinSuccess, "#osu")
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteChannelRevoked(stream, "#osu")
+	})
+	expectPacket(t, packet, BanchoChannelRevoked, "#osu")
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuChannelJoin(stream, "#lobby")
+	})
+	expectPacket(t, packet, OsuChannelJoin, "#lobby")
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuChannelLeave(stream, "#lobby")
+	})
+	expectPacket(t, packet, OsuChannelLeave, "#lobby")
+}
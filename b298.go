@@ -0,0 +1,306 @@
+package chio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+)
+
+// b298 adds the first implementation of multiplayer, which
+// includes the lobby, match creation and slot management.
+type b298 struct {
+	*b294
+}
+
+func (client *b298) WriteMatchUpdate(stream io.Writer, match Match) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteMatch(writer, match)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, BanchoMatchUpdate, writer.Bytes())
+}
+
+func (client *b298) WriteMatchNew(stream io.Writer, match Match) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteMatch(writer, match)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, BanchoMatchNew, writer.Bytes())
+}
+
+func (client *b298) WriteMatchDisband(stream io.Writer, matchId int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeInt32(writer, matchId)
+	return client.BanchoIO.WritePacket(stream, BanchoMatchDisband, writer.Bytes())
+}
+
+func (client *b298) WriteLobbyJoin(stream io.Writer, userId int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeInt32(writer, userId)
+	return client.BanchoIO.WritePacket(stream, BanchoLobbyJoin, writer.Bytes())
+}
+
+func (client *b298) WriteLobbyPart(stream io.Writer, userId int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeInt32(writer, userId)
+	return client.BanchoIO.WritePacket(stream, BanchoLobbyPart, writer.Bytes())
+}
+
+func (client *b298) WriteMatchJoinSuccess(stream io.Writer, match Match) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteMatch(writer, match)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, BanchoMatchJoinSuccess, writer.Bytes())
+}
+
+func (client *b298) WriteMatchJoinFail(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, BanchoMatchJoinFail, []byte{})
+}
+
+func (client *b298) WriteMatchStart(stream io.Writer, match Match) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteMatch(writer, match)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, BanchoMatchStart, writer.Bytes())
+}
+
+func (client *b298) WriteOsuLobbyPart(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuLobbyPart, []byte{})
+}
+
+func (client *b298) WriteOsuLobbyJoin(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuLobbyJoin, []byte{})
+}
+
+func (client *b298) WriteOsuMatchCreate(stream io.Writer, match Match) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteMatch(writer, match)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, OsuMatchCreate, writer.Bytes())
+}
+
+func (client *b298) WriteOsuMatchJoin(stream io.Writer, join MatchJoin) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeInt32(writer, join.MatchId)
+	return client.BanchoIO.WritePacket(stream, OsuMatchJoin, writer.Bytes())
+}
+
+func (client *b298) WriteOsuMatchPart(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuMatchPart, []byte{})
+}
+
+func (client *b298) WriteOsuMatchChangeSlot(stream io.Writer, slotId int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeInt32(writer, slotId)
+	return client.BanchoIO.WritePacket(stream, OsuMatchChangeSlot, writer.Bytes())
+}
+
+func (client *b298) WriteOsuMatchReady(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuMatchReady, []byte{})
+}
+
+func (client *b298) WriteOsuMatchLock(stream io.Writer, slotId int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	writeInt32(writer, slotId)
+	return client.BanchoIO.WritePacket(stream, OsuMatchLock, writer.Bytes())
+}
+
+func (client *b298) WriteOsuMatchChangeSettings(stream io.Writer, match Match) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := client.WriteMatch(writer, match)
+	if err != nil {
+		return err
+	}
+
+	return client.BanchoIO.WritePacket(stream, OsuMatchChangeSettings, writer.Bytes())
+}
+
+func (client *b298) WriteOsuMatchStart(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuMatchStart, []byte{})
+}
+
+func (client *b298) WriteMatch(writer io.Writer, match Match) error {
+	if match.Id < 0 || match.Id > math.MaxUint8 {
+		// The match id was only a single byte back then
+		return &FieldError{Field: "Match.Id", Err: ErrValueOutOfRange}
+	}
+
+	writeUint8(writer, uint8(match.Id))
+	return client.writeMatchData(writer, match)
+}
+
+// writeMatchData writes everything of the match after its id
+func (client *b298) writeMatchData(writer io.Writer, match Match) error {
+	slotSize := client.MatchSlotSize()
+	writeBoolean(writer, match.InProgress)
+	writeUint8(writer, match.Type)
+	writeString(writer, match.Name)
+	writeString(writer, match.BeatmapText)
+	writeInt32(writer, match.BeatmapId)
+	writeString(writer, match.BeatmapChecksum)
+
+	for i := 0; i < slotSize; i++ {
+		writeUint8(writer, client.slotAt(match, i).Status)
+	}
+
+	for i := 0; i < slotSize; i++ {
+		slot := client.slotAt(match, i)
+		if !slot.HasPlayer() {
+			continue
+		}
+		writeInt32(writer, slot.UserId)
+	}
+
+	writeInt32(writer, match.HostId)
+	return nil
+}
+
+func (client *b298) ReadMatch(reader io.Reader) (*Match, error) {
+	fields := newFieldReader(reader, "Match")
+	match := &Match{}
+
+	match.Id = int32(readField(fields, "Id", readUint8))
+	client.readMatchData(fields, match)
+
+	return match, fields.Err()
+}
+
+// readMatchData reads everything of the match after its id
+func (client *b298) readMatchData(fields *fieldReader, match *Match) {
+	match.InProgress = readField(fields, "InProgress", readBoolean)
+	match.Type = readField(fields, "Type", readUint8)
+	match.Name = readField(fields, "Name", readString)
+	match.BeatmapText = readField(fields, "BeatmapText", readString)
+	match.BeatmapId = readField(fields, "BeatmapId", readInt32)
+	match.BeatmapChecksum = readField(fields, "BeatmapChecksum", readString)
+
+	match.Slots = make([]*MatchSlot, client.MatchSlotSize())
+
+	for i := range match.Slots {
+		match.Slots[i] = &MatchSlot{}
+		match.Slots[i].Status = readField(fields, fmt.Sprintf("Slots[%d].Status", i), readUint8)
+	}
+
+	for i, slot := range match.Slots {
+		if !slot.HasPlayer() {
+			continue
+		}
+		slot.UserId = readField(fields, fmt.Sprintf("Slots[%d].UserId", i), readInt32)
+	}
+
+	match.HostId = readField(fields, "HostId", readInt32)
+}
+
+func (client *b298) ReadMatchJoin(reader io.Reader) (*MatchJoin, error) {
+	matchId, err := readInt32(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Match passwords have not been implemented yet
+	return &MatchJoin{MatchId: matchId}, nil
+}
+
+// slotAt returns the slot at the given index, or an empty
+// slot if the match does not contain enough slots
+func (client *b298) slotAt(match Match, index int) *MatchSlot {
+	if index >= len(match.Slots) || match.Slots[index] == nil {
+		return &MatchSlot{Status: SlotStatusOpen}
+	}
+	return match.Slots[index]
+}
+
+func (client *b298) Clone() BanchoIO {
+	clone := client.clone()
+	clone.BanchoIO = clone
+	return clone
+}
+
+func (client *b298) clone() *b298 {
+	return &b298{client.b294.clone()}
+}
+
+func newB298() *b298 {
+	client := &b298{newB294()}
+	client.BanchoIO = client
+	client.version = 298
+
+	client.readers[OsuMatchCreate] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMatch(reader)
+	}
+	client.readers[OsuMatchJoin] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMatchJoin(reader)
+	}
+	client.readers[OsuMatchChangeSlot] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[OsuMatchLock] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[OsuMatchChangeSettings] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMatch(reader)
+	}
+	client.readers[BanchoMatchUpdate] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMatch(reader)
+	}
+	client.readers[BanchoMatchNew] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMatch(reader)
+	}
+	client.readers[BanchoMatchDisband] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[BanchoLobbyJoin] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[BanchoLobbyPart] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[BanchoMatchJoinSuccess] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMatch(reader)
+	}
+	client.readers[BanchoMatchStart] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadMatch(reader)
+	}
+
+	client.supportedPackets = append(
+		client.supportedPackets,
+		BanchoMatchUpdate,
+		BanchoMatchNew,
+		BanchoMatchDisband,
+		OsuLobbyPart,
+		OsuLobbyJoin,
+		OsuMatchCreate,
+		OsuMatchJoin,
+		OsuMatchPart,
+		BanchoLobbyJoin,
+		BanchoLobbyPart,
+		BanchoMatchJoinSuccess,
+		BanchoMatchJoinFail,
+		OsuMatchChangeSlot,
+		OsuMatchReady,
+		OsuMatchLock,
+		OsuMatchChangeSettings,
+		OsuMatchStart,
+		BanchoMatchStart,
+	)
+
+	return client
+}
+
+func init() {
+	RegisterClient(298, newB298())
+}
@@ -0,0 +1,99 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func newTestMatch(id int32) Match {
+	slots := make([]*MatchSlot, 8)
+	for i := range slots {
+		slots[i] = &MatchSlot{Status: SlotStatusOpen}
+	}
+	slots[0] = &MatchSlot{Status: SlotStatusNotReady, UserId: 2}
+	slots[3] = &MatchSlot{Status: SlotStatusReady, UserId: 3}
+	slots[7] = &MatchSlot{Status: SlotStatusLocked}
+
+	return Match{
+		Id:              id,
+		InProgress:      true,
+		Type:            1,
+		Name:            "peppy's game",
+		BeatmapText:     "Kenji Ninuma - DISCOTHEQUE",
+		BeatmapId:       75,
+		BeatmapChecksum: "a5b99395a42bd55bc5eb1d2411cbdf8b",
+		Slots:           slots,
+		HostId:          2,
+	}
+}
+
+func TestB298Match(t *testing.T) {
+	client := newTestClient(t, 298)
+	match := newTestMatch(255)
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMatchUpdate(stream, match)
+	})
+	expectPacket(t, packet, BanchoMatchUpdate, &match)
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuMatchCreate(stream, match)
+	})
+	expectPacket(t, packet, OsuMatchCreate, &match)
+}
+
+func TestB298MatchSlotSize(t *testing.T) {
+	client := newTestClient(t, 298)
+	client.OverrideMatchSlotSize(16)
+	match := newTestMatch(1)
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMatchNew(stream, match)
+	})
+
+	// Missing slots are sent as open slots
+	for i := 8; i < 16; i++ {
+		match.Slots = append(match.Slots, &MatchSlot{Status: SlotStatusOpen})
+	}
+	expectPacket(t, packet, BanchoMatchNew, &match)
+}
+
+func TestB298MatchIdOutOfRange(t *testing.T) {
+	client := newTestClient(t, 298)
+	stream := bytes.NewBuffer([]byte{})
+
+	err := client.WriteMatchUpdate(stream, newTestMatch(300))
+	if !errors.Is(err, ErrValueOutOfRange) {
+		t.Fatalf("expected ErrValueOutOfRange, got %v", err)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("expected no packet to be written, got %d bytes", stream.Len())
+	}
+}
+
+func TestB298MatchJoin(t *testing.T) {
+	client := newTestClient(t, 298)
+	join := MatchJoin{MatchId: 300}
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuMatchJoin(stream, join)
+	})
+	expectPacket(t, packet, OsuMatchJoin, &join)
+}
+
+func TestB282MatchReaders(t *testing.T) {
+	client := newTestClient(t, 282)
+	var unsupported *ErrUnsupportedPacket
+
+	_, err := client.ReadMatch(bytes.NewReader(nil))
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected ErrUnsupportedPacket, got %v", err)
+	}
+
+	_, err = client.ReadMatchJoin(bytes.NewReader(nil))
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected ErrUnsupportedPacket, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+)
+
+// b323 appends the score frame of the player to spectator frames,
+// which allows spectators to see the live score, combo and hp.
+// Score frames are also sent to the other players of a match,
+// and players report when they have completed the match.
+type b323 struct {
+	*b298
+}
+
+func (client *b323) WriteSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteFrameBundle(writer, bundle)
+	return client.BanchoIO.WritePacket(stream, BanchoSpectateFrames, writer.Bytes())
+}
+
+func (client *b323) WriteOsuSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteFrameBundle(writer, bundle)
+	return client.BanchoIO.WritePacket(stream, OsuSpectateFrames, writer.Bytes())
+}
+
+func (client *b323) WriteMatchScoreUpdate(stream io.Writer, frame ScoreFrame) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteScoreFrame(writer, frame)
+	return client.BanchoIO.WritePacket(stream, BanchoMatchScoreUpdate, writer.Bytes())
+}
+
+func (client *b323) WriteOsuMatchScoreUpdate(stream io.Writer, frame ScoreFrame) error {
+	writer := bytes.NewBuffer([]byte{})
+	client.WriteScoreFrame(writer, frame)
+	return client.BanchoIO.WritePacket(stream, OsuMatchScoreUpdate, writer.Bytes())
+}
+
+func (client *b323) WriteOsuMatchComplete(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, OsuMatchComplete, []byte{})
+}
+
+func (client *b323) ScoreFrameChecksum(frame ScoreFrame) (string, error) {
+	return frame.Checksum(), nil
+}
+
+func (client *b323) WriteFrameBundle(writer io.Writer, bundle ReplayFrameBundle) error {
+	client.b298.WriteFrameBundle(writer, bundle)
+
+	if bundle.Frame != nil {
+		client.WriteScoreFrame(writer, *bundle.Frame)
+	}
+	return nil
+}
+
+func (client *b323) WriteScoreFrame(writer io.Writer, frame ScoreFrame) error {
+	writeInt32(writer, frame.Time)
+	writeUint8(writer, frame.Id)
+	writeUint16(writer, frame.Total300)
+	writeUint16(writer, frame.Total100)
+	writeUint16(writer, frame.Total50)
+	writeUint16(writer, frame.TotalGeki)
+	writeUint16(writer, frame.TotalKatu)
+	writeUint16(writer, frame.TotalMiss)
+	writeUint32(writer, frame.TotalScore)
+	writeUint16(writer, frame.MaxCombo)
+	writeUint16(writer, frame.CurrentCombo)
+	writeBoolean(writer, frame.Perfect)
+	writeUint8(writer, frame.Hp)
+	return nil
+}
+
+func (client *b323) ReadFrameBundle(reader io.Reader) (*ReplayFrameBundle, error) {
+	bundle, err := client.b298.ReadFrameBundle(reader)
+	if err != nil {
+		return bundle, err
+	}
+
+	if !hasRemainingData(reader) {
+		// The score frame is only sent while playing
+		return bundle, nil
+	}
+
+	fields := newFieldReader(reader, "ReplayFrameBundle")
+	bundle.Frame = readField(fields, "Frame", client.BanchoIO.ReadScoreFrame)
+
+	return bundle, fields.Err()
+}
+
+func (client *b323) ReadScoreFrame(reader io.Reader) (*ScoreFrame, error) {
+	fields := newFieldReader(reader, "ScoreFrame")
+	frame := &ScoreFrame{}
+	frame.Time = readField(fields, "Time", readInt32)
+	frame.Id = readField(fields, "Id", readUint8)
+	frame.Total300 = readField(fields, "Total300", readUint16)
+	frame.Total100 = readField(fields, "Total100", readUint16)
+	frame.Total50 = readField(fields, "Total50", readUint16)
+	frame.TotalGeki = readField(fields, "TotalGeki", readUint16)
+	frame.TotalKatu = readField(fields, "TotalKatu", readUint16)
+	frame.TotalMiss = readField(fields, "TotalMiss", readUint16)
+	frame.TotalScore = readField(fields, "TotalScore", readUint32)
+	frame.MaxCombo = readField(fields, "MaxCombo", readUint16)
+	frame.CurrentCombo = readField(fields, "CurrentCombo", readUint16)
+	frame.Perfect = readField(fields, "Perfect", readBoolean)
+	frame.Hp = readField(fields, "Hp", readUint8)
+
+	return frame, fields.Err()
+}
+
+func (client *b323) Clone() BanchoIO {
+	clone := client.clone()
+	clone.BanchoIO = clone
+	return clone
+}
+
+func (client *b323) clone() *b323 {
+	return &b323{client.b298.clone()}
+}
+
+func newB323() *b323 {
+	client := &b323{newB298()}
+	client.BanchoIO = client
+	client.version = 323
+
+	client.readers[OsuMatchScoreUpdate] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadScoreFrame(reader)
+	}
+	client.readers[BanchoMatchScoreUpdate] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return c.ReadScoreFrame(reader)
+	}
+
+	client.supportedPackets = append(
+		client.supportedPackets,
+		OsuMatchScoreUpdate,
+		BanchoMatchScoreUpdate,
+		OsuMatchComplete,
+	)
+
+	return client
+}
+
+func init() {
+	RegisterClient(323, newB323())
+}
@@ -0,0 +1,82 @@
+package chio
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func newTestScoreFrame() ScoreFrame {
+	return ScoreFrame{
+		Time:         52000,
+		Id:           3,
+		Total300:     312,
+		Total100:     12,
+		Total50:      2,
+		TotalGeki:    80,
+		TotalKatu:    7,
+		TotalMiss:    1,
+		TotalScore:   2450000,
+		MaxCombo:     301,
+		CurrentCombo: 25,
+		Perfect:      false,
+		Hp:           180,
+	}
+}
+
+func TestB323SpectateFrames(t *testing.T) {
+	client := newTestClient(t, 323)
+	frame := newTestScoreFrame()
+	bundle := newTestFrameBundle()
+	bundle.Frame = &frame
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteSpectateFrames(stream, bundle)
+	})
+	expectPacket(t, packet, BanchoSpectateFrames, &bundle)
+
+	// The score frame is only sent while playing
+	bundle.Frame = nil
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuSpectateFrames(stream, bundle)
+	})
+	expectPacket(t, packet, OsuSpectateFrames, &bundle)
+}
+
+func TestB323MatchScoreUpdate(t *testing.T) {
+	client := newTestClient(t, 323)
+	frame := newTestScoreFrame()
+
+	packet := roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteMatchScoreUpdate(stream, frame)
+	})
+	expectPacket(t, packet, BanchoMatchScoreUpdate, &frame)
+
+	packet = roundTrip(t, client, func(stream io.Writer) error {
+		return client.WriteOsuMatchScoreUpdate(stream, frame)
+	})
+	expectPacket(t, packet, OsuMatchScoreUpdate, &frame)
+}
+
+func TestB323ScoreFrameChecksum(t *testing.T) {
+	frame := newTestScoreFrame()
+
+	checksum, err := newTestClient(t, 323).ScoreFrameChecksum(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksum != frame.Checksum() {
+		t.Fatalf("expected checksum %s, got %s", frame.Checksum(), checksum)
+	}
+
+	// Score frames were added in b323
+	for _, version := range []int{282, 294, 298} {
+		_, err := newTestClient(t, version).ScoreFrameChecksum(frame)
+
+		var unsupported *ErrUnsupportedPacket
+		if !errors.As(err, &unsupported) {
+			t.Errorf("b%d: expected ErrUnsupportedPacket, got %v", version, err)
+		}
+	}
+}
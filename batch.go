@@ -0,0 +1,104 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PacketBatch collects packets in memory, so that they can be sent
+// to a stream with a single write. It implements io.Writer, which
+// means that it can be passed to any of the packet writers.
+// A PacketBatch is safe for concurrent use.
+type PacketBatch struct {
+	buffer bytes.Buffer
+	ends   []int // end offsets of the packets in the buffer
+	mutex  sync.Mutex
+}
+
+// NewPacketBatch creates an empty packet batch
+func NewPacketBatch() *PacketBatch {
+	return &PacketBatch{}
+}
+
+// Write appends an encoded packet to the batch.
+// Every packet writer calls this exactly once per packet.
+func (batch *PacketBatch) Write(p []byte) (int, error) {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	n, err := batch.buffer.Write(p)
+	batch.ends = append(batch.ends, batch.buffer.Len())
+	return n, err
+}
+
+// Len returns the size of all packets in the batch in bytes
+func (batch *PacketBatch) Len() int {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	return batch.buffer.Len()
+}
+
+// Count returns the amount of packets in the batch. Every call to Write
+// is counted as one packet, which is the case for all packet writers,
+// but not for data that is written to the batch by other means.
+func (batch *PacketBatch) Count() int {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	return len(batch.ends)
+}
+
+// Bytes returns a copy of all packets in the batch
+func (batch *PacketBatch) Bytes() []byte {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	return bytes.Clone(batch.buffer.Bytes())
+}
+
+// Reset removes all packets from the batch
+func (batch *PacketBatch) Reset() {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+	batch.buffer.Reset()
+	batch.ends = batch.ends[:0]
+}
+
+// Flush writes all packets to the stream in a single write, and empties the batch.
+// If the write fails, the packets that were not written at all will remain in the
+// batch. Packets that were only written partially are dropped, so that the next
+// flush doesn't start in the middle of a packet.
+func (batch *PacketBatch) Flush(stream io.Writer) error {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+
+	if batch.buffer.Len() == 0 {
+		return nil
+	}
+
+	n, err := stream.Write(batch.buffer.Bytes())
+	if err != nil {
+		batch.dropWritten(n)
+		return err
+	}
+
+	batch.buffer.Reset()
+	batch.ends = batch.ends[:0]
+	return nil
+}
+
+// dropWritten removes all packets, that were at least partially written
+func (batch *PacketBatch) dropWritten(n int) {
+	offset := 0
+	dropped := 0
+
+	for dropped < len(batch.ends) && offset < n {
+		offset = batch.ends[dropped]
+		dropped++
+	}
+
+	batch.buffer.Next(offset)
+	batch.ends = append(batch.ends[:0], batch.ends[dropped:]...)
+
+	for i := range batch.ends {
+		batch.ends[i] -= offset
+	}
+}
@@ -0,0 +1,99 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// failingWriter accepts a limited amount of bytes, before failing
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return w.limit, errors.New("connection reset")
+	}
+	return len(p), nil
+}
+
+func TestPacketBatch(t *testing.T) {
+	client := newTestClient(t, 294)
+	batch := NewPacketBatch()
+	expected := bytes.NewBuffer([]byte{})
+
+	client.WriteLoginReply(batch, 2)
+	client.WriteChannelJoinSuccess(batch, "#osu")
+	client.WritePing(batch)
+	client.WriteLoginReply(expected, 2)
+	client.WriteChannelJoinSuccess(expected, "#osu")
+	client.WritePing(expected)
+
+	if batch.Count() != 3 {
+		t.Fatalf("expected 3 packets, got %d", batch.Count())
+	}
+	if batch.Len() != expected.Len() {
+		t.Fatalf("expected %d bytes, got %d", expected.Len(), batch.Len())
+	}
+
+	stream := bytes.NewBuffer([]byte{})
+	if err := batch.Flush(stream); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stream.Bytes(), expected.Bytes()) {
+		t.Fatalf("expected %v, got %v", expected.Bytes(), stream.Bytes())
+	}
+	if batch.Len() != 0 || batch.Count() != 0 {
+		t.Fatalf("expected batch to be empty after flushing, got %d packets", batch.Count())
+	}
+}
+
+func TestPacketBatchFlushError(t *testing.T) {
+	client := newTestClient(t, 294)
+	first := bytes.NewBuffer([]byte{})
+	second := bytes.NewBuffer([]byte{})
+	client.WriteChannelJoinSuccess(first, "#osu")
+	client.WritePing(second)
+
+	tests := []struct {
+		limit    int
+		expected []byte
+		count    int
+	}{
+		// Nothing was written, so both packets remain
+		{0, append(bytes.Clone(first.Bytes()), second.Bytes()...), 2},
+		// The first packet was cut off, and can't be completed on another stream
+		{4, second.Bytes(), 1},
+		// The first packet was fully written
+		{first.Len(), second.Bytes(), 1},
+		// The second packet was cut off
+		{first.Len() + 1, []byte{}, 0},
+	}
+
+	for _, test := range tests {
+		batch := NewPacketBatch()
+		batch.Write(first.Bytes())
+		batch.Write(second.Bytes())
+
+		if err := batch.Flush(&failingWriter{limit: test.limit}); err == nil {
+			t.Fatal("expected flush to fail")
+		}
+
+		if !bytes.Equal(batch.Bytes(), test.expected) {
+			t.Errorf("limit %d: expected %v to remain, got %v", test.limit, test.expected, batch.Bytes())
+		}
+		if batch.Count() != test.count {
+			t.Errorf("limit %d: expected %d packets to remain, got %d", test.limit, test.count, batch.Count())
+		}
+
+		// The remaining packets are sent with the next flush
+		stream := bytes.NewBuffer([]byte{})
+		if err := batch.Flush(stream); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(stream.Bytes(), test.expected) {
+			t.Errorf("limit %d: expected %v to be flushed, got %v", test.limit, test.expected, stream.Bytes())
+		}
+	}
+}
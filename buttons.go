@@ -0,0 +1,51 @@
+package chio
+
+// ButtonStateConversion is the result of converting a button state
+// to the representation that is used by a specific client version
+type ButtonStateConversion struct {
+	// ButtonState is the state as it will be received by the client
+	ButtonState uint8
+
+	// Lossy contains the bits of the original state that were dropped.
+	// Bits that were added by the conversion are not included.
+	Lossy uint8
+}
+
+// IsLossy checks if the conversion lost any information
+func (conversion ButtonStateConversion) IsLossy() bool {
+	return conversion.Lossy != 0
+}
+
+// Older clients only send whether the left & right mouse buttons are
+// pressed. Keyboard keys will be folded into the mouse button on the same
+// side, and smoke is dropped entirely. When reading those frames, the buttons
+// are mapped to ButtonStateLeft1 & ButtonStateRight1, so keyboard input will
+// come back as mouse input.
+
+// legacyButtons converts the button state into the two mouse buttons
+func legacyButtons(state uint8) (left bool, right bool) {
+	left = state&(ButtonStateLeft1|ButtonStateLeft2) > 0
+	right = state&(ButtonStateRight1|ButtonStateRight2) > 0
+	return left, right
+}
+
+// buttonStateFromLegacy converts the two mouse buttons into a button state
+func buttonStateFromLegacy(left bool, right bool) uint8 {
+	state := ButtonStateNoButton
+
+	if left {
+		state |= ButtonStateLeft1
+	}
+	if right {
+		state |= ButtonStateRight1
+	}
+
+	return state
+}
+
+// convertLegacyButtonState returns the state as it will be received
+// by clients, that only send the two mouse buttons
+func convertLegacyButtonState(state uint8) ButtonStateConversion {
+	converted := buttonStateFromLegacy(legacyButtons(state))
+	return ButtonStateConversion{ButtonState: converted, Lossy: state &^ converted}
+}
@@ -0,0 +1,42 @@
+package chio
+
+import "testing"
+
+func TestConvertLegacyButtonState(t *testing.T) {
+	tests := []struct {
+		state    uint8
+		expected ButtonStateConversion
+	}{
+		{ButtonStateNoButton, ButtonStateConversion{ButtonState: ButtonStateNoButton}},
+		{ButtonStateLeft1, ButtonStateConversion{ButtonState: ButtonStateLeft1}},
+		{ButtonStateLeft1 | ButtonStateRight1, ButtonStateConversion{ButtonState: ButtonStateLeft1 | ButtonStateRight1}},
+
+		// Keyboard keys are folded into the mouse buttons, which is not reported as lost
+		{ButtonStateLeft2, ButtonStateConversion{ButtonState: ButtonStateLeft1, Lossy: ButtonStateLeft2}},
+		{ButtonStateRight2, ButtonStateConversion{ButtonState: ButtonStateRight1, Lossy: ButtonStateRight2}},
+		{ButtonStateLeft1 | ButtonStateLeft2, ButtonStateConversion{ButtonState: ButtonStateLeft1, Lossy: ButtonStateLeft2}},
+		{ButtonStateSmoke, ButtonStateConversion{ButtonState: ButtonStateNoButton, Lossy: ButtonStateSmoke}},
+	}
+
+	client := newTestClient(t, 282)
+
+	for _, test := range tests {
+		conversion := client.ConvertButtonState(test.state)
+		if conversion != test.expected {
+			t.Errorf("state %d: expected %+v, got %+v", test.state, test.expected, conversion)
+		}
+		if conversion.IsLossy() != (test.expected.Lossy != 0) {
+			t.Errorf("state %d: expected lossy to be %v", test.state, test.expected.Lossy != 0)
+		}
+	}
+}
+
+func TestConvertButtonState(t *testing.T) {
+	client := newTestClient(t, 20121223)
+	state := ButtonStateLeft2 | ButtonStateRight1 | ButtonStateSmoke
+
+	conversion := client.ConvertButtonState(state)
+	if conversion.ButtonState != state || conversion.IsLossy() {
+		t.Fatalf("expected the full button state to be kept, got %+v", conversion)
+	}
+}
@@ -1,6 +1,14 @@
 package chio
 
-import "io"
+//go:generate go run ./internal/chiogen -schema protocol.json -output packets_gen.go
+
+import (
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+)
 
 // BanchoPacket is a struct that represents a packet that
 // is sent or received
@@ -9,6 +17,28 @@ type BanchoPacket struct {
 	Data interface{}
 }
 
+// UnknownPacket is the data of a packet that was skipped, because
+// it's either not supported by the client or could not be decoded
+type UnknownPacket struct {
+	Payload []byte
+	Err     error
+}
+
+// RawPacket is a packet whose payload has not been decoded, which
+// allows it to be inspected or forwarded without knowing its structure
+type RawPacket struct {
+	Id      uint16
+	Payload []byte
+}
+
+// PacketHeader contains the information that
+// precedes the data of every packet
+type PacketHeader struct {
+	Id         uint16
+	Length     int32
+	Compressed bool
+}
+
 // BanchoIO is an interface that wraps the basic methods for
 // reading and writing packets to a Bancho client
 type BanchoIO interface {
@@ -18,12 +48,35 @@ type BanchoIO interface {
 	// ReadPacket reads a packet from the provided stream
 	ReadPacket(stream io.Reader) (packet *BanchoPacket, err error)
 
+	// ReadPacketHeader reads only the header of a packet from the provided stream
+	ReadPacketHeader(stream io.Reader) (header PacketHeader, err error)
+
+	// ReadRawPacket reads a packet from the provided stream, without decoding its payload.
+	// Unlike ReadPacket, this will also return packets that are not supported by the client.
+	ReadRawPacket(stream io.Reader) (packet *RawPacket, err error)
+
+	// WriteRawPacket writes an already encoded packet to the provided stream.
+	// Packets that are not supported by the client will be dropped.
+	WriteRawPacket(stream io.Writer, packet RawPacket) error
+
+	// ConvertInputPacketId converts a packet id, as sent by the client, to the packet id constants
+	ConvertInputPacketId(packetId uint16) uint16
+
+	// ConvertOutputPacketId converts a packet id constant to the id, that is sent to the client
+	ConvertOutputPacketId(packetId uint16) uint16
+
+	// DecodePayload decodes the payload of a packet, using the reader for the packetId
+	DecodePayload(packetId uint16, payload []byte) (any, error)
+
 	// SupportedPackets returns a list of packetIds that are supported by the client
 	SupportedPackets() []uint16
 
 	// ImplementsPacket checks if the packetId is implemented in the client
 	ImplementsPacket(packetId uint16) bool
 
+	// Version returns the client version that is implemented, e.g. 282
+	Version() int
+
 	// ProtocolVersion returns the bancho protocol version used by the client
 	ProtocolVersion() int
 
@@ -36,17 +89,61 @@ type BanchoIO interface {
 	// OverrideMatchSlotSize lets you specify a custom amount of slots to read & write to the client
 	OverrideMatchSlotSize(amount int)
 
+	// MaxPacketSize returns the maximum length of a packet that will be read from the client
+	MaxPacketSize() int
+
+	// OverrideMaxPacketSize lets you specify a custom maximum packet length in bytes
+	OverrideMaxPacketSize(size int)
+
+	// MaxDecompressedSize returns the maximum size that packet data may decompress to
+	MaxDecompressedSize() int
+
+	// OverrideMaxDecompressedSize lets you specify a custom maximum decompressed size in bytes
+	OverrideMaxDecompressedSize(size int)
+
+	// MaxCompressionRatio returns the maximum ratio between decompressed and compressed packet data
+	MaxCompressionRatio() int
+
+	// OverrideMaxCompressionRatio lets you specify a custom compression ratio limit, where 0 disables the check
+	OverrideMaxCompressionRatio(ratio int)
+
+	// SkipUnknownPackets returns whether unsupported or undecodable packets will be skipped
+	SkipUnknownPackets() bool
+
+	// OverrideSkipUnknownPackets lets you return unsupported or undecodable packets as an
+	// UnknownPacket, instead of failing with an error that leaves the stream unusable
+	OverrideSkipUnknownPackets(skip bool)
+
+	// ScoreFrameChecksum calculates the checksum of a score frame, as done by the client.
+	// The checksum is the same for all versions with score frames, see ScoreFrame.Checksum.
+	// Versions without score frames return ErrUnsupportedPacket.
+	ScoreFrameChecksum(frame ScoreFrame) (string, error)
+
+	// ConvertButtonState returns the button state of a replay frame as it will be
+	// received by the client, and reports the information that will be lost
+	ConvertButtonState(state uint8) ButtonStateConversion
+
 	// GetReaders returns the packet reader registry
 	GetReaders() ReaderRegistry
 
+	// Clone returns an independent copy of the client, including its overrides
+	Clone() BanchoIO
+
 	// Packet writers
 	BanchoWriters
+
+	// Packet readers
+	BanchoReaders
+
+	// Packet writers for the client side
+	OsuWriters
 }
 
 // BanchoWriters is an interface that wraps the methods for writing
 // to a Bancho client
 type BanchoWriters interface {
 	WriteLoginReply(stream io.Writer, reply int32) error
+	WriteCommandError(stream io.Writer) error
 	WriteMessage(stream io.Writer, message Message) error
 	WritePing(stream io.Writer) error
 	WriteIrcChangeUsername(stream io.Writer, oldName, newName string) error
@@ -106,33 +203,129 @@ type BanchoWriters interface {
 	WriteSwitchTournamentServer(stream io.Writer, ip string) error
 }
 
-var clients map[int]BanchoIO = make(map[int]BanchoIO)
+// OsuWriters is an interface that wraps the methods for writing
+// to a Bancho server, i.e. acting as an osu! client
+type OsuWriters interface {
+	WriteOsuUserStatus(stream io.Writer, status UserStatus) error
+	WriteOsuMessage(stream io.Writer, message Message) error
+	WriteOsuExit(stream io.Writer) error
+	WriteOsuStatusUpdateRequest(stream io.Writer) error
+	WriteOsuPong(stream io.Writer) error
+	WriteOsuStartSpectating(stream io.Writer, userId int32) error
+	WriteOsuStopSpectating(stream io.Writer) error
+	WriteOsuSpectateFrames(stream io.Writer, bundle ReplayFrameBundle) error
+	WriteOsuErrorReport(stream io.Writer, report string) error
+	WriteOsuCantSpectate(stream io.Writer) error
+	WriteOsuPrivateMessage(stream io.Writer, message Message) error
+	WriteOsuChannelJoin(stream io.Writer, channel string) error
+	WriteOsuChannelLeave(stream io.Writer, channel string) error
+	WriteOsuLobbyPart(stream io.Writer) error
+	WriteOsuLobbyJoin(stream io.Writer) error
+	WriteOsuMatchCreate(stream io.Writer, match Match) error
+	WriteOsuMatchJoin(stream io.Writer, join MatchJoin) error
+	WriteOsuMatchPart(stream io.Writer) error
+	WriteOsuMatchChangeSlot(stream io.Writer, slotId int32) error
+	WriteOsuMatchReady(stream io.Writer) error
+	WriteOsuMatchLock(stream io.Writer, slotId int32) error
+	WriteOsuMatchChangeSettings(stream io.Writer, match Match) error
+	WriteOsuMatchStart(stream io.Writer) error
+	WriteOsuMatchScoreUpdate(stream io.Writer, frame ScoreFrame) error
+	WriteOsuMatchComplete(stream io.Writer) error
+}
 
-const lowestVersion int = 282
-const highestVersion int = 290
+// BanchoReaders is an interface that wraps the methods for reading
+// packet data from a Bancho client
+type BanchoReaders interface {
+	ReadStatus(reader io.Reader) (*UserStatus, error)
+	ReadStats(reader io.Reader) (*UserInfo, error)
+	ReadPresence(reader io.Reader) (*UserInfo, error)
+	ReadMessage(reader io.Reader) (*Message, error)
+	ReadFrameBundle(reader io.Reader) (*ReplayFrameBundle, error)
+	ReadReplayFrame(reader io.Reader) (*ReplayFrame, error)
+	ReadScoreFrame(reader io.Reader) (*ScoreFrame, error)
+	ReadMatch(reader io.Reader) (*Match, error)
+	ReadMatchJoin(reader io.Reader) (*MatchJoin, error)
+	ReadChannel(reader io.Reader) (*Channel, error)
+}
 
-// GetClientInterface returns a BanchoIO interface for the given client version
-func GetClientInterface(clientVersion int) BanchoIO {
-	if clientVersion < lowestVersion {
-		client := clients[lowestVersion]
-		return client
+// defaultMaxPacketSize is the default limit for the length of incoming packets
+const defaultMaxPacketSize int = 4 * 1024 * 1024
+
+// defaultMaxDecompressedSize is the default limit for the size of decompressed packet data
+const defaultMaxDecompressedSize int = 4 * 1024 * 1024
+
+// defaultMaxCompressionRatio is the default limit for the compression ratio of packet data
+const defaultMaxCompressionRatio int = 100
+
+// registeredClient is a BanchoIO implementation for a specific version
+type registeredClient struct {
+	version int
+	client  BanchoIO
+}
+
+// clients holds all registered implementations, sorted by version
+var clients []registeredClient
+var clientsMutex sync.RWMutex
+
+// RegisterClient registers a BanchoIO implementation for the given client version,
+// which will be used for every version up to the next registered one.
+// A previously registered implementation for the same version will be replaced.
+func RegisterClient(version int, client BanchoIO) {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+
+	index := sort.Search(len(clients), func(i int) bool {
+		return clients[i].version >= version
+	})
+
+	if index < len(clients) && clients[index].version == version {
+		clients[index].client = client
+		return
 	}
 
-	if clientVersion > highestVersion {
-		client := clients[highestVersion]
-		return client
+	clients = slices.Insert(clients, index, registeredClient{version, client})
+}
+
+// SupportedVersions returns every version that has its own implementation, in ascending order
+func SupportedVersions() []int {
+	clientsMutex.RLock()
+	defer clientsMutex.RUnlock()
+
+	versions := make([]int, len(clients))
+	for i, registered := range clients {
+		versions[i] = registered.version
 	}
+	return versions
+}
 
-	if client, ok := clients[clientVersion]; ok {
-		return client
+// GetClientInterface returns a BanchoIO interface for the given client version.
+// It uses the nearest implemented version that is lower or equal to the client version,
+// or the lowest implemented version if the client is older than that.
+//
+// Every call returns a new instance, so that overrides like OverrideMatchSlotSize
+// only apply to a single connection. An instance can be used to read & write
+// packets from multiple goroutines, as long as it's not overridden concurrently.
+func GetClientInterface(clientVersion int) BanchoIO {
+	clientsMutex.RLock()
+	defer clientsMutex.RUnlock()
+
+	if len(clients) == 0 {
+		return nil
 	}
 
-	// Find the next compatible version
-	for version, client := range clients {
-		if version <= clientVersion {
-			return client
-		}
+	// Find the first version that is newer than the client
+	index := sort.Search(len(clients), func(i int) bool {
+		return clients[i].version > clientVersion
+	})
+
+	if index == 0 {
+		return clients[0].client.Clone()
 	}
 
-	return nil
+	return clients[index-1].client.Clone()
+}
+
+// getLatestClientInterface returns the implementation for the newest supported version
+func getLatestClientInterface() BanchoIO {
+	return GetClientInterface(math.MaxInt)
 }
@@ -0,0 +1,158 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// newTestClient returns the implementation that is registered for the version
+func newTestClient(t testing.TB, version int) BanchoIO {
+	t.Helper()
+	client := GetClientInterface(version)
+	if client.Version() != version {
+		t.Fatalf("expected client version %d, got %d", version, client.Version())
+	}
+	return client
+}
+
+// roundTrip writes a single packet and reads it back with the same client
+func roundTrip(t testing.TB, client BanchoIO, write func(stream io.Writer) error) *BanchoPacket {
+	t.Helper()
+	stream := bytes.NewBuffer([]byte{})
+
+	if err := write(stream); err != nil {
+		t.Fatalf("failed to write packet: %v", err)
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatalf("failed to read packet: %v", err)
+	}
+
+	if stream.Len() > 0 {
+		t.Fatalf("%d bytes left after reading packet %d", stream.Len(), packet.Id)
+	}
+	return packet
+}
+
+// expectPacket checks the id & data of a packet
+func expectPacket(t testing.TB, packet *BanchoPacket, id uint16, data any) {
+	t.Helper()
+	if packet.Id != id {
+		t.Fatalf("expected packet %d, got %d", id, packet.Id)
+	}
+	if !reflect.DeepEqual(packet.Data, data) {
+		t.Fatalf("packet %d: expected %#v, got %#v", id, data, packet.Data)
+	}
+}
+
+func TestGetClientInterface(t *testing.T) {
+	tests := []struct {
+		clientVersion int
+		expected      int
+	}{
+		{0, 282},
+		{282, 282},
+		{293, 282},
+		{294, 294},
+		{20120811, 323},
+		{20120812, 20120812},
+		{20121222, 20120812},
+		{20121223, 20121223},
+		{20121224, 20121223},
+		{20160402, 20121223},
+		{20160403, 20160403},
+	}
+
+	for _, test := range tests {
+		client := GetClientInterface(test.clientVersion)
+		if client.Version() != test.expected {
+			t.Errorf("version %d: expected %d, got %d", test.clientVersion, test.expected, client.Version())
+		}
+	}
+}
+
+func TestSupportedVersions(t *testing.T) {
+	expected := []int{282, 294, 298, 323, 20120812, 20121223, 20160403}
+	if !reflect.DeepEqual(SupportedVersions(), expected) {
+		t.Fatalf("expected versions %v, got %v", expected, SupportedVersions())
+	}
+}
+
+func TestClientOverridesAreIndependent(t *testing.T) {
+	first := GetClientInterface(298)
+	second := GetClientInterface(298)
+	first.OverrideMatchSlotSize(16)
+
+	if second.MatchSlotSize() != 8 {
+		t.Fatalf("override of one client changed another client to %d slots", second.MatchSlotSize())
+	}
+}
+
+// restoreClients restores the registered clients after the test
+func restoreClients(t *testing.T) {
+	clientsMutex.RLock()
+	registered := slices.Clone(clients)
+	clientsMutex.RUnlock()
+
+	t.Cleanup(func() {
+		clientsMutex.Lock()
+		clients = registered
+		clientsMutex.Unlock()
+	})
+}
+
+// newMarkedClient returns a b20121223 client, that can be identified by its protocol version
+func newMarkedClient(marker int) BanchoIO {
+	client := newB20121223()
+	client.OverrideProtocolVersion(marker)
+	return client
+}
+
+func TestRegisterClient(t *testing.T) {
+	restoreClients(t)
+
+	// Versions are registered out of order
+	RegisterClient(20150000, newMarkedClient(1))
+	RegisterClient(1000, newMarkedClient(2))
+
+	expected := []int{282, 294, 298, 323, 1000, 20120812, 20121223, 20150000, 20160403}
+	if !reflect.DeepEqual(SupportedVersions(), expected) {
+		t.Fatalf("expected versions %v, got %v", expected, SupportedVersions())
+	}
+
+	// Registering a version again replaces the previous client
+	RegisterClient(20150000, newMarkedClient(3))
+
+	if !reflect.DeepEqual(SupportedVersions(), expected) {
+		t.Fatalf("expected versions %v, got %v", expected, SupportedVersions())
+	}
+
+	tests := []struct {
+		clientVersion int
+		marker        int
+		version       int
+	}{
+		{999, 0, 323},
+		{1000, 2, 20121223},
+		{20120811, 2, 20121223},
+		{20121223, 0, 20121223},
+		{20149999, 0, 20121223},
+		{20150000, 3, 20121223},
+		{20160402, 3, 20121223},
+		{20160403, 0, 20160403},
+	}
+
+	for _, test := range tests {
+		client := GetClientInterface(test.clientVersion)
+		if client.ProtocolVersion() != test.marker || client.Version() != test.version {
+			t.Errorf(
+				"version %d: expected client %d with marker %d, got %d with marker %d",
+				test.clientVersion, test.version, test.marker, client.Version(), client.ProtocolVersion(),
+			)
+		}
+	}
+}
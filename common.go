@@ -5,7 +5,10 @@ import (
 	"compress/gzip"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"math"
+	"sync"
 
 	"github.com/bnch/uleb128"
 )
@@ -122,8 +125,7 @@ func compressData(data []byte) []byte {
 }
 
 func readUint64(r io.Reader) (v uint64, err error) {
-	err = binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	return readLittleEndian(r, 8)
 }
 
 func readInt64(r io.Reader) (v int64, err error) {
@@ -132,8 +134,8 @@ func readInt64(r io.Reader) (v int64, err error) {
 }
 
 func readUint32(r io.Reader) (v uint32, err error) {
-	err = binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	uv, err := readLittleEndian(r, 4)
+	return uint32(uv), err
 }
 
 func readInt32(r io.Reader) (v int32, err error) {
@@ -142,8 +144,8 @@ func readInt32(r io.Reader) (v int32, err error) {
 }
 
 func readUint16(r io.Reader) (v uint16, err error) {
-	err = binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	uv, err := readLittleEndian(r, 2)
+	return uint16(uv), err
 }
 
 func readInt16(r io.Reader) (v int16, err error) {
@@ -152,8 +154,14 @@ func readInt16(r io.Reader) (v int16, err error) {
 }
 
 func readUint8(r io.Reader) (v uint8, err error) {
-	err = binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	if br, ok := r.(io.ByteReader); ok {
+		// Avoids allocating a buffer for single bytes
+		return br.ReadByte()
+	}
+
+	var buf [1]byte
+	_, err = io.ReadFull(r, buf[:])
+	return buf[0], err
 }
 
 func readInt8(r io.Reader) (v int8, err error) {
@@ -162,18 +170,18 @@ func readInt8(r io.Reader) (v int8, err error) {
 }
 
 func readBoolean(r io.Reader) (v bool, err error) {
-	err = binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	b, err := readUint8(r)
+	return b != 0, err
 }
 
 func readFloat32(r io.Reader) (v float32, err error) {
-	err = binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	uv, err := readUint32(r)
+	return math.Float32frombits(uv), err
 }
 
 func readFloat64(r io.Reader) (v float64, err error) {
-	err = binary.Read(r, binary.LittleEndian, &v)
-	return v, err
+	uv, err := readUint64(r)
+	return math.Float64frombits(uv), err
 }
 
 func readIntList16(r io.Reader) (v []int32, err error) {
@@ -224,8 +232,7 @@ func readBoolList(r io.Reader) ([]bool, error) {
 }
 
 func readString(r io.Reader) (v string, err error) {
-	var b uint8
-	err = binary.Read(r, binary.LittleEndian, &b)
+	b, err := readUint8(r)
 	if err != nil {
 		return "", err
 	}
@@ -235,29 +242,271 @@ func readString(r io.Reader) (v string, err error) {
 	}
 
 	if b != 0x0b {
-		return "", errors.New("invalid string type")
+		return "", ErrInvalidString
+	}
+
+	l, err := readUleb128(r)
+	if err != nil {
+		return "", err
 	}
 
-	l := uleb128.UnmarshalReader(r)
-	buf := make([]byte, l)
-	_, err = r.Read(buf)
+	// Copy the string in chunks, so that a bogus length
+	// won't allocate more memory than we actually receive
+	buf := bytes.NewBuffer([]byte{})
+	_, err = io.CopyN(buf, r, int64(l))
+	if err == io.EOF {
+		return "", fmt.Errorf("%w: length %d exceeds the data: %w", ErrInvalidString, l, io.ErrUnexpectedEOF)
+	}
 	if err != nil {
 		return "", err
 	}
 
-	return string(buf), nil
+	return buf.String(), nil
 }
 
-func decompressData(data []byte) ([]byte, error) {
-	if len(data) == 0 {
-		return []byte{}, nil
+func readUleb128(r io.Reader) (v int, err error) {
+	var shift uint
+
+	for {
+		b, err := readUint8(r)
+		if err == io.EOF && shift > 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		v |= int(b&0x7F) << shift
+		if b&0x80 == 0 {
+			break
+		}
+
+		shift += 7
+		if shift >= 63 {
+			return 0, errors.New("uleb128 value is too large")
+		}
+	}
+
+	return v, nil
+}
+
+// readLittleEndian reads an unsigned integer of up to 8 bytes. Readers that
+// implement io.ByteReader are read byte by byte, because passing a buffer
+// to an io.Reader interface would move it to the heap for every value.
+func readLittleEndian(r io.Reader, size int) (uint64, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		var buf [8]byte
+		_, err := io.ReadFull(r, buf[:size])
+		return binary.LittleEndian.Uint64(buf[:]), err
+	}
+
+	var v uint64
+	for i := 0; i < size; i++ {
+		b, err := br.ReadByte()
+		if err == io.EOF && i > 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		v |= uint64(b) << (8 * i)
+	}
+
+	return v, nil
+}
+
+// hasRemainingData checks if there is any data left in the reader.
+// Readers that can't tell are assumed to contain more data.
+func hasRemainingData(reader io.Reader) bool {
+	if r, ok := reader.(interface{ Len() int }); ok {
+		return r.Len() > 0
+	}
+	return true
+}
+
+// readPacketData reads the full packet data of the given length from the stream,
+// even if it arrives in multiple chunks
+func readPacketData(stream io.Reader, length int32, limit int) ([]byte, error) {
+	err := validatePacketLength(length, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, length)
+	_, err = io.ReadFull(stream, data)
+	if err != nil {
+		return nil, shortPacketError(err)
+	}
+
+	return data, nil
+}
+
+// shortPacketError marks an unexpected end of the stream as ErrShortPacket
+func shortPacketError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %w", ErrShortPacket, io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+// decodePacket runs the reader on the packet data, and adds
+// the packet id & offset to any error that occurred
+func decodePacket(client BanchoIO, reader PacketReader, packetId uint16, payload *bytes.Reader) (any, error) {
+	data, err := reader(client, payload)
+	if err != nil {
+		offset := payload.Size() - int64(payload.Len())
+		return nil, newPacketError(packetId, offset, err)
+	}
+	return data, nil
+}
+
+func validatePacketLength(length int32, limit int) error {
+	if length < 0 {
+		// Lengths are unsigned, so this would be above 2 GB
+		return fmt.Errorf("%w: invalid length %d", ErrPacketTooLarge, length)
+	}
+	if int(length) > limit {
+		return fmt.Errorf("%w: length %d exceeds limit of %d bytes", ErrPacketTooLarge, length, limit)
 	}
+	return nil
+}
+
+// decompressionLimit returns the maximum size that compressed
+// data of the given size is allowed to decompress to
+func decompressionLimit(client BanchoIO, compressedSize int) int {
+	limit := client.MaxDecompressedSize()
+
+	if ratio := client.MaxCompressionRatio(); ratio > 0 {
+		limit = min(limit, compressedSize*ratio)
+	}
+
+	return limit
+}
+
+// decompressor holds a gzip reader along with its input, so that
+// both can be reused between packets without allocating
+type decompressor struct {
+	gzip    gzip.Reader
+	source  bytes.Reader
+	limited io.LimitedReader
+}
+
+// decompressors holds the decompressors that are not in use
+var decompressors sync.Pool
+
+// decompressData decompresses gzip data, and fails with a
+// DecompressionLimitError if the output grows larger than limit
+func decompressData(data []byte, limit int) ([]byte, error) {
 	dst := bytes.NewBuffer([]byte{})
-	zr, err := gzip.NewReader(bytes.NewReader(data))
+	err := decompressInto(dst, data, limit)
 	if err != nil {
 		return nil, err
 	}
-	io.Copy(dst, zr)
-	zr.Close()
 	return dst.Bytes(), nil
 }
+
+// decompressInto works like decompressData, but writes to an existing buffer
+func decompressInto(dst *bytes.Buffer, data []byte, limit int) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	d, ok := decompressors.Get().(*decompressor)
+	if !ok {
+		d = &decompressor{}
+	}
+	defer decompressors.Put(d)
+	defer d.source.Reset(nil)
+
+	d.source.Reset(data)
+	err := d.gzip.Reset(&d.source)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDecompress, err)
+	}
+	defer d.gzip.Close()
+
+	// Read one byte past the limit, to find out if it was exceeded
+	d.limited = io.LimitedReader{R: &d.gzip, N: int64(limit) + 1}
+	n, err := io.Copy(dst, &d.limited)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDecompress, err)
+	}
+	if n > int64(limit) {
+		return &DecompressionLimitError{CompressedSize: len(data), Limit: limit}
+	}
+
+	return nil
+}
+
+// fieldReader reads the fields of a packet, and collects the errors
+// that occurred along with the name of the field. Once the end of
+// the data was reached, all remaining fields will be skipped.
+type fieldReader struct {
+	reader   io.Reader
+	typeName string
+	errors   []error
+	eof      bool
+}
+
+func newFieldReader(reader io.Reader, typeName string) *fieldReader {
+	return &fieldReader{reader: reader, typeName: typeName}
+}
+
+// Err returns a DecodeError, or nil if every field was read successfully
+func (fr *fieldReader) Err() error {
+	if len(fr.errors) == 0 {
+		return nil
+	}
+	return &DecodeError{Errors: fr.errors}
+}
+
+func (fr *fieldReader) add(field string, err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		fr.eof = true
+	}
+	fr.errors = append(fr.errors, &FieldError{Field: fr.typeName + "." + field, Err: err})
+}
+
+// read runs the read function for a single field,
+// unless the end of the data was already reached
+func (fr *fieldReader) read(field string, read func(io.Reader) error) {
+	if fr.eof {
+		return
+	}
+	fr.add(field, read(fr.reader))
+}
+
+// readField reads a single field, unless the end of the data was already reached
+func readField[T any](fr *fieldReader, field string, read func(io.Reader) (T, error)) T {
+	var v T
+	fr.read(field, func(r io.Reader) (err error) {
+		v, err = read(r)
+		return err
+	})
+	return v
+}
+
+// readList reads a list field with the given amount of elements.
+// It stops at the first element that could not be read, and skips
+// all remaining fields, because their position would be unknown.
+func readList[T any](fr *fieldReader, field string, count int, read func(io.Reader) (T, error)) []T {
+	if fr.eof {
+		return nil
+	}
+
+	list := make([]T, count)
+	for i := range list {
+		v, err := read(fr.reader)
+		if err != nil {
+			fr.add(fmt.Sprintf("%s[%d]", field, i), err)
+			fr.eof = true
+			return list[:i]
+		}
+		list[i] = v
+	}
+	return list
+}
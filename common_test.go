@@ -0,0 +1,218 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadPacketDataLength(t *testing.T) {
+	tests := []struct {
+		length int32
+		limit  int
+	}{
+		{-1, 1024},
+		{math.MinInt32, 1024},
+		{1025, 1024},
+		{math.MaxInt32, defaultMaxPacketSize},
+	}
+
+	for _, test := range tests {
+		stream := bytes.NewReader(make([]byte, 2048))
+		_, err := readPacketData(stream, test.length, test.limit)
+		if !errors.Is(err, ErrPacketTooLarge) {
+			t.Errorf("length %d: expected ErrPacketTooLarge, got %v", test.length, err)
+		}
+		if stream.Len() != 2048 {
+			t.Errorf("length %d: expected no data to be read, got %d bytes", test.length, 2048-stream.Len())
+		}
+	}
+
+	data, err := readPacketData(bytes.NewReader(make([]byte, 1024)), 1024, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1024 {
+		t.Fatalf("expected 1024 bytes, got %d", len(data))
+	}
+
+	_, err = readPacketData(bytes.NewReader(make([]byte, 10)), 20, 1024)
+	if !errors.Is(err, ErrShortPacket) {
+		t.Fatalf("expected ErrShortPacket, got %v", err)
+	}
+}
+
+func TestReadPacketPartialStream(t *testing.T) {
+	client := newTestClient(t, 294)
+	message := Message{Sender: "peppy", Content: "hello", Target: "#osu"}
+
+	data := bytes.NewBuffer([]byte{})
+	client.WriteMessage(data, message)
+	client.WritePing(data)
+
+	// Every read only returns a single byte, like a slow connection would
+	stream := iotest.OneByteReader(data)
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoSendMessage, &message)
+
+	packet, err = client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoPing, nil)
+
+	if _, err = client.ReadPacket(stream); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
+
+func TestReadStringPartialStream(t *testing.T) {
+	data := bytes.NewBuffer([]byte{})
+	writeString(data, "hello, world!")
+
+	v, err := readString(iotest.OneByteReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "hello, world!" {
+		t.Fatalf("expected %q, got %q", "hello, world!", v)
+	}
+}
+
+func TestReadStringInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"type byte", []byte{0x0a, 0x05, 'h', 'e', 'l', 'l', 'o'}},
+		{"length", []byte{0x0b, 0x06, 'h', 'e', 'l', 'l', 'o'}},
+		{"huge length", []byte{0x0b, 0xff, 0xff, 0xff, 0xff, 0x0f, 'h'}},
+	}
+
+	for _, test := range tests {
+		_, err := readString(bytes.NewReader(test.data))
+		if !errors.Is(err, ErrInvalidString) {
+			t.Errorf("%s: expected ErrInvalidString, got %v", test.name, err)
+		}
+	}
+
+	// Strings that are longer than the packet are reported as a short packet
+	client := newTestClient(t, 294)
+	stream := bytes.NewBuffer([]byte{})
+	client.WritePacket(stream, OsuErrorReport, []byte{0x0b, 0x06, 'h', 'e', 'l', 'l', 'o'})
+
+	_, err := client.ReadPacket(stream)
+	if !errors.Is(err, ErrInvalidString) || !errors.Is(err, ErrShortPacket) {
+		t.Fatalf("expected ErrInvalidString & ErrShortPacket, got %v", err)
+	}
+}
+
+func TestOverrideMaxPacketSize(t *testing.T) {
+	client := newTestClient(t, 294)
+	if client.MaxPacketSize() != defaultMaxPacketSize {
+		t.Fatalf("expected default limit of %d, got %d", defaultMaxPacketSize, client.MaxPacketSize())
+	}
+
+	data := bytes.NewBuffer([]byte{})
+	client.WritePacket(data, OsuErrorReport, []byte{0x0b, 0x05, 'h', 'e', 'l', 'l', 'o'})
+	packet := data.Bytes()
+
+	// The compressed payload is larger than the limit
+	client.OverrideMaxPacketSize(8)
+	if client.MaxPacketSize() != 8 {
+		t.Fatalf("expected limit of 8, got %d", client.MaxPacketSize())
+	}
+
+	_, err := client.ReadPacket(bytes.NewReader(packet))
+	if !errors.Is(err, ErrPacketTooLarge) {
+		t.Fatalf("expected ErrPacketTooLarge, got %v", err)
+	}
+
+	client.OverrideMaxPacketSize(len(packet))
+	result, err := client.ReadPacket(bytes.NewReader(packet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, result, OsuErrorReport, "hello")
+}
+
+func TestDecompressionBomb(t *testing.T) {
+	client := newTestClient(t, 282)
+
+	// Zeros compress extremely well, so this is only a few kilobytes
+	stream := bytes.NewBuffer([]byte{})
+	client.WritePacket(stream, OsuErrorReport, make([]byte, 16*1024*1024))
+
+	_, err := client.ReadPacket(stream)
+	if !errors.Is(err, ErrPacketTooLarge) {
+		t.Fatalf("expected ErrPacketTooLarge, got %v", err)
+	}
+
+	var limitErr *DecompressionLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected a DecompressionLimitError, got %T", err)
+	}
+	if limitErr.Limit != limitErr.CompressedSize*defaultMaxCompressionRatio {
+		t.Fatalf("expected the ratio limit to apply, got %d for %d bytes", limitErr.Limit, limitErr.CompressedSize)
+	}
+}
+
+func TestDecompressTruncated(t *testing.T) {
+	compressed := compressData([]byte("hello, world!"))
+
+	for _, length := range []int{4, len(compressed) / 2, len(compressed) - 4} {
+		_, err := decompressData(compressed[:length], defaultMaxDecompressedSize)
+		if !errors.Is(err, ErrDecompress) {
+			t.Errorf("length %d: expected ErrDecompress, got %v", length, err)
+		}
+	}
+
+	// Truncated packets fail the same way
+	client := newTestClient(t, 282)
+	stream := bytes.NewBuffer([]byte{})
+	writeUint16(stream, OsuErrorReport)
+	writeUint32(stream, uint32(len(compressed)/2))
+	stream.Write(compressed[:len(compressed)/2])
+
+	if _, err := client.ReadPacket(stream); !errors.Is(err, ErrDecompress) {
+		t.Fatalf("expected ErrDecompress, got %v", err)
+	}
+}
+
+func TestOverrideMaxDecompressedSize(t *testing.T) {
+	client := newTestClient(t, 282)
+	client.OverrideMaxCompressionRatio(0)
+
+	data := bytes.NewBuffer([]byte{})
+	writeString(data, "hello, world!")
+
+	stream := bytes.NewBuffer([]byte{})
+	client.WritePacket(stream, OsuErrorReport, data.Bytes())
+	packet := stream.Bytes()
+
+	client.OverrideMaxDecompressedSize(data.Len() - 1)
+	if client.MaxDecompressedSize() != data.Len()-1 {
+		t.Fatalf("expected limit of %d, got %d", data.Len()-1, client.MaxDecompressedSize())
+	}
+
+	_, err := client.ReadPacket(bytes.NewReader(packet))
+	var limitErr *DecompressionLimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != data.Len()-1 {
+		t.Fatalf("expected a DecompressionLimitError with limit %d, got %v", data.Len()-1, err)
+	}
+
+	// The limit is inclusive
+	client.OverrideMaxDecompressedSize(data.Len())
+	result, err := client.ReadPacket(bytes.NewReader(packet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, result, OsuErrorReport, "hello, world!")
+}
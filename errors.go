@@ -1,62 +1,134 @@
 package chio
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
 
-type ErrorCollection struct {
-	errors   []error
-	position int
+var (
+	// ErrInvalidString is returned when a string does not start with a valid type byte,
+	// or when its length exceeds the remaining data
+	ErrInvalidString = errors.New("invalid string")
+
+	// ErrShortPacket is returned when a packet contains less data than expected
+	ErrShortPacket = errors.New("packet is shorter than expected")
+
+	// ErrPacketTooLarge is returned when a packet exceeds the configured size limits
+	ErrPacketTooLarge = errors.New("packet is too large")
+
+	// ErrDecompress is returned when the packet data could not be decompressed
+	ErrDecompress = errors.New("failed to decompress packet data")
+
+	// ErrValueOutOfRange is returned when a value does not fit into the field it is written to
+	ErrValueOutOfRange = errors.New("value is out of range")
+)
+
+// ErrUnsupportedPacket is returned when a packet is read,
+// which is not implemented by the client version
+type ErrUnsupportedPacket struct {
+	Id      uint16
+	Version int
 }
 
-func (ec *ErrorCollection) Add(err error) {
-	if err == nil {
-		return
-	}
-	ec.errors = append(ec.errors, err)
+func (e *ErrUnsupportedPacket) Error() string {
+	return fmt.Sprintf("packet '%d' not implemented in version %d", e.Id, e.Version)
 }
 
-func (ec *ErrorCollection) Pop(index int) error {
-	if !ec.HasErrors() {
-		return nil
-	}
-	err := ec.errors[index]
-	ec.errors = append(ec.errors[:index], ec.errors[index+1:]...)
-	return err
+// PacketError adds the packet id and the offset inside
+// the packet data to an error that occurred while decoding
+type PacketError struct {
+	Id     uint16
+	Offset int64
+	Err    error
 }
 
-func (ec *ErrorCollection) Next() error {
-	if !ec.HasErrors() {
-		return nil
-	}
-	if ec.position >= len(ec.errors) {
-		return nil
+func (e *PacketError) Error() string {
+	return fmt.Sprintf("packet '%d' at offset %d: %v", e.Id, e.Offset, e.Err)
+}
+
+func (e *PacketError) Unwrap() error {
+	return e.Err
+}
+
+// newPacketError wraps the error in a PacketError, and marks
+// unexpected ends of the packet data as ErrShortPacket
+func newPacketError(packetId uint16, offset int64, err error) *PacketError {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = fmt.Errorf("%w: %w", ErrShortPacket, err)
 	}
-	err := ec.errors[ec.position]
-	ec.position++
+	return &PacketError{Id: packetId, Offset: offset, Err: err}
+}
+
+// PanicError is returned by HandlePanic, when a panic was recovered
+type PanicError struct {
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
 	return err
 }
 
-func (ec *ErrorCollection) Errors() []error {
-	return ec.errors
+// FieldError is returned when a single field of a packet could not be read
+type FieldError struct {
+	// Field is the name of the field, e.g. "UserStatus.BeatmapChecksum"
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
 }
 
-func (ec *ErrorCollection) Length() int {
-	return len(ec.errors)
+func (e *FieldError) Unwrap() error {
+	return e.Err
 }
 
-func (ec *ErrorCollection) String() string {
-	return fmt.Sprintf("ErrorCollection{errors: %v}", len(ec.errors))
+// DecodeError contains every error that occurred while reading
+// the fields of a packet. Like the errors returned by errors.Join,
+// it can be inspected with errors.Is and errors.As.
+type DecodeError struct {
+	Errors []error
 }
 
-func (ec *ErrorCollection) HasErrors() bool {
-	return len(ec.errors) > 0
+func (e *DecodeError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
 }
 
-func NewErrorCollection() *ErrorCollection {
-	return &ErrorCollection{}
+func (e *DecodeError) Unwrap() []error {
+	return e.Errors
 }
 
 func HandlePanic(err *error) {
 	if r := recover(); r != nil {
-		*err = fmt.Errorf("panic: %v", r)
+		*err = &PanicError{Value: r}
 	}
 }
+
+// DecompressionLimitError is returned when the decompressed packet data
+// exceeds the configured size or compression ratio limits
+type DecompressionLimitError struct {
+	CompressedSize int
+	Limit          int
+}
+
+func (e *DecompressionLimitError) Is(target error) bool {
+	return target == ErrPacketTooLarge
+}
+
+func (e *DecompressionLimitError) Error() string {
+	return fmt.Sprintf(
+		"decompressed data exceeds limit of %d bytes (compressed size: %d bytes)",
+		e.Limit, e.CompressedSize,
+	)
+}
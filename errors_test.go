@@ -0,0 +1,178 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// newTestDecodeError reads a status with an invalid text and a truncated
+// beatmap checksum, which results in a DecodeError with two field errors
+func newTestDecodeError() error {
+	fields := newFieldReader(bytes.NewReader([]byte{0x0a, 0x0b, 0x05, 'a'}), "UserStatus")
+	readField(fields, "Text", readString)
+	readField(fields, "BeatmapChecksum", readString)
+	readField(fields, "Mods", readUint32)
+	return fields.Err()
+}
+
+func newTestPanicError(value any) (err error) {
+	defer HandlePanic(&err)
+	panic(value)
+}
+
+func TestDecodeErrorUnwrap(t *testing.T) {
+	err := newTestDecodeError()
+
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a DecodeError, got %T", err)
+	}
+
+	// Fields after the end of the data are skipped
+	errs := decodeErr.Unwrap()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+
+	expected := []struct {
+		field    string
+		sentinel error
+	}{
+		{"UserStatus.Text", ErrInvalidString},
+		{"UserStatus.BeatmapChecksum", io.ErrUnexpectedEOF},
+	}
+
+	for i, test := range expected {
+		var fieldErr *FieldError
+		if !errors.As(errs[i], &fieldErr) {
+			t.Fatalf("error %d: expected a FieldError, got %T", i, errs[i])
+		}
+		if fieldErr.Field != test.field {
+			t.Errorf("error %d: expected field %q, got %q", i, test.field, fieldErr.Field)
+		}
+		if !errors.Is(fieldErr, test.sentinel) {
+			t.Errorf("error %d: expected %v, got %v", i, test.sentinel, fieldErr.Err)
+		}
+
+		// Both errors are reachable from the DecodeError itself
+		if !errors.Is(err, test.sentinel) {
+			t.Errorf("expected the DecodeError to match %v", test.sentinel)
+		}
+	}
+}
+
+func TestHandlePanic(t *testing.T) {
+	err := newTestPanicError("something went wrong")
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("expected a PanicError, got %T", err)
+	}
+	if panicErr.Value != "something went wrong" {
+		t.Fatalf("expected the panic value, got %v", panicErr.Value)
+	}
+	if panicErr.Unwrap() != nil {
+		t.Fatalf("expected no wrapped error, got %v", panicErr.Unwrap())
+	}
+
+	// Panics with an error value can be inspected
+	err = newTestPanicError(io.ErrUnexpectedEOF)
+	if !errors.As(err, &panicErr) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected a PanicError wrapping the panic value, got %v", err)
+	}
+
+	// Functions that don't panic keep their error
+	err = func() (err error) {
+		defer HandlePanic(&err)
+		return ErrShortPacket
+	}()
+	if err != ErrShortPacket {
+		t.Fatalf("expected the returned error, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var (
+		unsupported *ErrUnsupportedPacket
+		packetErr   *PacketError
+		fieldErr    *FieldError
+		decodeErr   *DecodeError
+		panicErr    *PanicError
+		limitErr    *DecompressionLimitError
+	)
+
+	tests := []struct {
+		name  string
+		err   error
+		is    []error
+		isNot []error
+		as    []any
+	}{
+		{
+			name:  "unsupported packet",
+			err:   &ErrUnsupportedPacket{Id: OsuChannelJoin, Version: 282},
+			as:    []any{&unsupported},
+			isNot: []error{ErrShortPacket},
+		},
+		{
+			name:  "field error",
+			err:   &FieldError{Field: "Match.Id", Err: ErrValueOutOfRange},
+			is:    []error{ErrValueOutOfRange},
+			isNot: []error{ErrInvalidString},
+			as:    []any{&fieldErr},
+		},
+		{
+			name: "decode error",
+			err:  newTestDecodeError(),
+			is:   []error{ErrInvalidString, io.ErrUnexpectedEOF},
+			as:   []any{&decodeErr, &fieldErr},
+		},
+		{
+			name:  "packet error",
+			err:   newPacketError(OsuSendUserStatus, 4, newTestDecodeError()),
+			is:    []error{ErrShortPacket, ErrInvalidString, io.ErrUnexpectedEOF},
+			isNot: []error{ErrPacketTooLarge},
+			as:    []any{&packetErr, &decodeErr, &fieldErr},
+		},
+		{
+			name:  "short packet",
+			err:   newPacketError(OsuErrorReport, 0, io.EOF),
+			is:    []error{ErrShortPacket, io.EOF},
+			isNot: []error{ErrInvalidString},
+			as:    []any{&packetErr},
+		},
+		{
+			name: "panic",
+			err:  newTestPanicError(ErrValueOutOfRange),
+			is:   []error{ErrValueOutOfRange},
+			as:   []any{&panicErr},
+		},
+		{
+			name:  "decompression limit",
+			err:   &DecompressionLimitError{CompressedSize: 10, Limit: 1000},
+			is:    []error{ErrPacketTooLarge},
+			isNot: []error{ErrDecompress},
+			as:    []any{&limitErr},
+		},
+	}
+
+	for _, test := range tests {
+		for _, target := range test.is {
+			if !errors.Is(test.err, target) {
+				t.Errorf("%s: expected errors.Is to match %v", test.name, target)
+			}
+		}
+		for _, target := range test.isNot {
+			if errors.Is(test.err, target) {
+				t.Errorf("%s: expected errors.Is not to match %v", test.name, target)
+			}
+		}
+		for _, target := range test.as {
+			if !errors.As(test.err, target) {
+				t.Errorf("%s: expected errors.As to find %T", test.name, target)
+			}
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package chio
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultSessionTimeout is the default duration after which idle sessions expire
+const defaultSessionTimeout = 5 * time.Minute
+
+// HttpSession represents a client that is logged in over http
+type HttpSession struct {
+	Token   string
+	Client  BanchoIO
+	Request *LoginRequest
+
+	// Queue holds the packets that will be sent on the next request
+	Queue *PacketBatch
+
+	// Data can be used to attach custom data to the session, e.g. a player
+	Data any
+
+	// lastActive is the time of the last request in unix nanoseconds
+	lastActive atomic.Int64
+}
+
+// LastActive returns the time of the last request of the session
+func (session *HttpSession) LastActive() time.Time {
+	return time.Unix(0, session.lastActive.Load())
+}
+
+func (session *HttpSession) touch(now time.Time) {
+	session.lastActive.Store(now.UnixNano())
+}
+
+// HttpHandler is a http.Handler that implements the bancho transport
+// used by later clients, where the client sends its packets inside of
+// POST requests, and receives the queued packets in the response.
+// It is safe for concurrent use.
+type HttpHandler struct {
+	// OnLogin is called for requests without a token. The session client is
+	// selected based on the version inside the login request, but can be changed.
+	// The session client skips unknown packets, which are passed to OnPacket as an UnknownPacket.
+	// The login response should be written to the session queue.
+	// Returning an error will reject the login attempt.
+	OnLogin func(session *HttpSession) error
+
+	// OnPacket is called for every packet that was received from a session
+	OnPacket func(session *HttpSession, packet *BanchoPacket) error
+
+	// OnSessionExpired is called for every session that was removed, because
+	// it didn't send any requests within the SessionTimeout
+	OnSessionExpired func(session *HttpSession)
+
+	// DefaultClient is used for requests with an unknown token,
+	// and for sessions where the client version could not be parsed
+	DefaultClient BanchoIO
+
+	// SessionTimeout is the duration after which sessions without any
+	// requests expire. Expired sessions are removed while serving requests.
+	// A timeout of zero disables the expiry.
+	SessionTimeout time.Duration
+
+	// ErrorLog is used to log errors that occurred while writing a response.
+	// If it is nil, the standard logger of the log package is used.
+	ErrorLog *log.Logger
+
+	sessions  map[string]*HttpSession
+	mutex     sync.RWMutex
+	lastSweep atomic.Int64
+}
+
+// NewHttpHandler creates a HttpHandler that uses the latest client by default
+func NewHttpHandler() *HttpHandler {
+	return &HttpHandler{
+		DefaultClient:  getLatestClientInterface(),
+		SessionTimeout: defaultSessionTimeout,
+		sessions:       make(map[string]*HttpSession),
+	}
+}
+
+// Session returns the session for the given token, unless it has expired
+func (handler *HttpHandler) Session(token string) (*HttpSession, bool) {
+	handler.mutex.RLock()
+	defer handler.mutex.RUnlock()
+	session, ok := handler.sessions[token]
+	if !ok || handler.expired(session, time.Now()) {
+		return nil, false
+	}
+	return session, true
+}
+
+// Sessions returns a list of all sessions that have not expired
+func (handler *HttpHandler) Sessions() []*HttpSession {
+	handler.mutex.RLock()
+	defer handler.mutex.RUnlock()
+
+	now := time.Now()
+	sessions := make([]*HttpSession, 0, len(handler.sessions))
+	for _, session := range handler.sessions {
+		if !handler.expired(session, now) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions
+}
+
+// RemoveExpiredSessions removes all sessions that have expired,
+// and calls OnSessionExpired for each of them
+func (handler *HttpHandler) RemoveExpiredSessions() {
+	now := time.Now()
+	expired := []*HttpSession{}
+
+	handler.mutex.Lock()
+	for token, session := range handler.sessions {
+		if handler.expired(session, now) {
+			expired = append(expired, session)
+			delete(handler.sessions, token)
+		}
+	}
+	handler.mutex.Unlock()
+
+	if handler.OnSessionExpired == nil {
+		return
+	}
+	for _, session := range expired {
+		handler.OnSessionExpired(session)
+	}
+}
+
+func (handler *HttpHandler) expired(session *HttpSession, now time.Time) bool {
+	if handler.SessionTimeout <= 0 {
+		return false
+	}
+	return now.Sub(session.LastActive()) > handler.SessionTimeout
+}
+
+// removeExpiredSessionsIfDue removes the expired sessions,
+// at most once within the duration of the SessionTimeout
+func (handler *HttpHandler) removeExpiredSessionsIfDue(now time.Time) {
+	if handler.SessionTimeout <= 0 {
+		return
+	}
+
+	last := handler.lastSweep.Load()
+	if now.UnixNano()-last < int64(handler.SessionTimeout) {
+		return
+	}
+	if !handler.lastSweep.CompareAndSwap(last, now.UnixNano()) {
+		// Another request is already removing them
+		return
+	}
+
+	handler.RemoveExpiredSessions()
+}
+
+// RemoveSession removes the session, e.g. after the client has logged out
+func (handler *HttpHandler) RemoveSession(token string) {
+	handler.mutex.Lock()
+	defer handler.mutex.Unlock()
+	delete(handler.sessions, token)
+}
+
+func (handler *HttpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+	handler.removeExpiredSessionsIfDue(now)
+
+	token := r.Header.Get("osu-token")
+
+	if token == "" {
+		handler.handleLogin(w, r)
+		return
+	}
+
+	session, ok := handler.Session(token)
+
+	if !ok {
+		// Make the client log in again
+		response := NewPacketBatch()
+		handler.DefaultClient.WriteRestart(response, 0)
+		handler.writeResponse(w, handler.DefaultClient, response)
+		return
+	}
+
+	session.touch(now)
+
+	body, err := readBody(r, session.Client.MaxPacketSize())
+	if err != nil {
+		writeBodyError(w, err)
+		return
+	}
+
+	stream := bytes.NewReader(body)
+
+	for stream.Len() > 0 {
+		packet, err := session.Client.ReadPacket(stream)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if handler.OnPacket == nil {
+			continue
+		}
+
+		err = handler.OnPacket(session, packet)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
+	handler.writeResponse(w, session.Client, session.Queue)
+}
+
+func (handler *HttpHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r, maxLoginRequestSize)
+	if err != nil {
+		writeBodyError(w, err)
+		return
+	}
+
+	request, err := ParseLoginRequest(body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	client, err := request.ClientInterface()
+	if err != nil {
+		client = handler.DefaultClient.Clone()
+	}
+
+	// A single unexpected packet should not reject the whole request
+	client.OverrideSkipUnknownPackets(true)
+
+	session := &HttpSession{
+		Token:   token,
+		Client:  client,
+		Request: request,
+		Queue:   NewPacketBatch(),
+	}
+	session.touch(time.Now())
+
+	if handler.OnLogin == nil {
+		err = errors.New("login handler not implemented")
+	} else {
+		err = handler.OnLogin(session)
+	}
+
+	if err != nil {
+		// The client will not send any further requests with this token
+		w.Header().Set("cho-token", "no")
+		handler.writeResponse(w, session.Client, session.Queue)
+		return
+	}
+
+	handler.mutex.Lock()
+	handler.sessions[token] = session
+	handler.mutex.Unlock()
+
+	w.Header().Set("cho-token", token)
+	handler.writeResponse(w, session.Client, session.Queue)
+}
+
+func (handler *HttpHandler) writeResponse(w http.ResponseWriter, client BanchoIO, queue *PacketBatch) {
+	// The protocol version is only known, if it was set with OverrideProtocolVersion
+	if version := client.ProtocolVersion(); version > 0 {
+		w.Header().Set("cho-protocol", strconv.Itoa(version))
+	}
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+
+	// Packets that were not written will be sent with the next response
+	err := queue.Flush(w)
+	if err != nil {
+		handler.logf("chio: failed to write response: %v", err)
+	}
+}
+
+func (handler *HttpHandler) logf(format string, args ...any) {
+	if handler.ErrorLog != nil {
+		handler.ErrorLog.Printf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
+
+// readBody reads the request body, and fails with
+// ErrPacketTooLarge if it is larger than the limit
+func readBody(r *http.Request, limit int) ([]byte, error) {
+	// Read one byte past the limit, to find out if it was exceeded
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > limit {
+		return nil, fmt.Errorf("%w: request body exceeds limit of %d bytes", ErrPacketTooLarge, limit)
+	}
+	return body, nil
+}
+
+func writeBodyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrPacketTooLarge) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	w.WriteHeader(http.StatusBadRequest)
+}
+
+func generateToken() (string, error) {
+	buffer := make([]byte, 16)
+	_, err := rand.Read(buffer)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,282 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLoginRequest = "peppy\n5f4dcc3b5aa765d61d8327deb882cf99\nb20130815|1|0|e7a8c0b3:00-1A-2B-3C-4D-5E.:6f1ed002:d41d8cd9:c4ca4238:|0\n"
+
+func newTestHttpHandler() *HttpHandler {
+	handler := NewHttpHandler()
+	handler.OnLogin = func(session *HttpSession) error {
+		if session.Request.Username != "peppy" {
+			return errors.New("unknown user")
+		}
+		return session.Client.WriteLoginReply(session.Queue, 2)
+	}
+	handler.OnPacket = func(session *HttpSession, packet *BanchoPacket) error {
+		if packet.Id == OsuPong {
+			return session.Client.WritePing(session.Queue)
+		}
+		return nil
+	}
+	return handler
+}
+
+func postBancho(handler http.Handler, token string, body []byte) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	if token != "" {
+		request.Header.Set("osu-token", token)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+// login logs in as peppy, and returns the session token
+func login(t *testing.T, handler *HttpHandler) string {
+	t.Helper()
+	response := postBancho(handler, "", []byte(testLoginRequest))
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.Code)
+	}
+
+	token := response.Header().Get("cho-token")
+	if token == "" || token == "no" {
+		t.Fatalf("expected a token, got '%s'", token)
+	}
+
+	client := newTestClient(t, 20121223)
+	packet, err := client.ReadPacket(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoLoginReply, int32(2))
+	return token
+}
+
+func TestHttpLogin(t *testing.T) {
+	handler := newTestHttpHandler()
+	token := login(t, handler)
+
+	session, ok := handler.Session(token)
+	if !ok {
+		t.Fatal("expected session to be stored")
+	}
+	if session.Client.Version() != 20121223 {
+		t.Fatalf("expected client version 20121223, got %d", session.Client.Version())
+	}
+	if session.Request.Version != "b20130815" {
+		t.Fatalf("expected version b20130815, got %s", session.Request.Version)
+	}
+}
+
+func TestHttpLoginRejected(t *testing.T) {
+	handler := newTestHttpHandler()
+	body := bytes.Replace([]byte(testLoginRequest), []byte("peppy"), []byte("nobody"), 1)
+	response := postBancho(handler, "", body)
+
+	if response.Header().Get("cho-token") != "no" {
+		t.Fatalf("expected login to be rejected, got token '%s'", response.Header().Get("cho-token"))
+	}
+	if len(handler.Sessions()) > 0 {
+		t.Fatal("expected no session to be stored")
+	}
+}
+
+func TestHttpPackets(t *testing.T) {
+	handler := newTestHttpHandler()
+	token := login(t, handler)
+	client := newTestClient(t, 20121223)
+
+	body := bytes.NewBuffer([]byte{})
+	client.WriteOsuPong(body)
+
+	// Unsupported packets should be skipped
+	client.WritePacket(body, OsuReceiveUpdates, []byte{0, 0, 0, 0})
+	client.WriteOsuPong(body)
+
+	response := postBancho(handler, token, body.Bytes())
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.Code)
+	}
+
+	for i := 0; i < 2; i++ {
+		packet, err := client.ReadPacket(response.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectPacket(t, packet, BanchoPing, nil)
+	}
+
+	if response.Body.Len() > 0 {
+		t.Fatalf("%d bytes left in the response", response.Body.Len())
+	}
+}
+
+func TestHttpUnknownToken(t *testing.T) {
+	handler := newTestHttpHandler()
+	response := postBancho(handler, "invalid", nil)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.Code)
+	}
+
+	packet, err := handler.DefaultClient.ReadPacket(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoRestart, int32(0))
+}
+
+func TestHttpBadRequest(t *testing.T) {
+	handler := newTestHttpHandler()
+
+	response := postBancho(handler, "", []byte("peppy\n"))
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("invalid login: expected status 400, got %d", response.Code)
+	}
+
+	token := login(t, handler)
+
+	// The packet header is cut off after the packet id
+	response = postBancho(handler, token, []byte{4, 0, 0})
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("invalid packet: expected status 400, got %d", response.Code)
+	}
+}
+
+func TestHttpProtocolHeader(t *testing.T) {
+	handler := newTestHttpHandler()
+
+	response := postBancho(handler, "", []byte(testLoginRequest))
+	if header := response.Header().Get("cho-protocol"); header != "" {
+		t.Fatalf("expected no protocol version, got '%s'", header)
+	}
+
+	login := handler.OnLogin
+	handler.OnLogin = func(session *HttpSession) error {
+		session.Client.OverrideProtocolVersion(19)
+		return login(session)
+	}
+
+	response = postBancho(handler, "", []byte(testLoginRequest))
+	if header := response.Header().Get("cho-protocol"); header != "19" {
+		t.Fatalf("expected protocol version 19, got '%s'", header)
+	}
+}
+
+func TestHttpRequestTooLarge(t *testing.T) {
+	handler := newTestHttpHandler()
+
+	body := append([]byte(testLoginRequest), make([]byte, maxLoginRequestSize)...)
+	response := postBancho(handler, "", body)
+	if response.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("login: expected status 413, got %d", response.Code)
+	}
+
+	token := login(t, handler)
+	session, _ := handler.Session(token)
+	session.Client.OverrideMaxPacketSize(16)
+
+	body = make([]byte, 17)
+	response = postBancho(handler, token, body)
+	if response.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("packets: expected status 413, got %d", response.Code)
+	}
+
+	// Bodies that are exactly at the limit are accepted
+	pong := bytes.NewBuffer([]byte{})
+	session.Client.WriteOsuPong(pong)
+	session.Client.OverrideMaxPacketSize(pong.Len())
+
+	response = postBancho(handler, token, pong.Bytes())
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", response.Code)
+	}
+}
+
+func TestHttpSessionTimeout(t *testing.T) {
+	handler := newTestHttpHandler()
+	handler.SessionTimeout = time.Minute
+
+	expired := []*HttpSession{}
+	handler.OnSessionExpired = func(session *HttpSession) {
+		expired = append(expired, session)
+	}
+
+	token := login(t, handler)
+	active := login(t, handler)
+	session, _ := handler.Session(token)
+	session.touch(time.Now().Add(-2 * time.Minute))
+
+	if _, ok := handler.Session(token); ok {
+		t.Fatal("expected the session to be expired")
+	}
+	if len(handler.Sessions()) != 1 {
+		t.Fatalf("expected 1 active session, got %d", len(handler.Sessions()))
+	}
+
+	// The client has to log in again
+	response := postBancho(handler, token, nil)
+	packet, err := handler.DefaultClient.ReadPacket(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoRestart, int32(0))
+
+	// Expired sessions are removed once the timeout has passed since the last removal
+	handler.lastSweep.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+	postBancho(handler, active, nil)
+
+	if len(expired) != 1 || expired[0] != session {
+		t.Fatalf("expected the session to be removed, got %v", expired)
+	}
+	if _, ok := handler.Session(active); !ok {
+		t.Fatal("expected the active session to remain")
+	}
+}
+
+// failingResponseWriter is a http.ResponseWriter that fails to write the body
+type failingResponseWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingResponseWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestHttpWriteError(t *testing.T) {
+	handler := newTestHttpHandler()
+	output := bytes.NewBuffer([]byte{})
+	handler.ErrorLog = log.New(output, "", 0)
+
+	token := login(t, handler)
+	session, _ := handler.Session(token)
+	session.Client.WritePing(session.Queue)
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	request.Header.Set("osu-token", token)
+	handler.ServeHTTP(failingResponseWriter{httptest.NewRecorder()}, request)
+
+	if !strings.Contains(output.String(), "connection reset") {
+		t.Fatalf("expected the error to be logged, got '%s'", output.String())
+	}
+
+	// The packet is sent with the next response
+	response := postBancho(handler, token, nil)
+	packet, err := session.Client.ReadPacket(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoPing, nil)
+}
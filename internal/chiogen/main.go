@@ -0,0 +1,599 @@
+// Command chiogen generates packet readers & writers for chio from a
+// protocol schema, so that simple packets don't need to be written by hand.
+// Next to the output, it generates a conformance test for every packet.
+//
+// Usage:
+//
+//	go run ./internal/chiogen -schema protocol.json -output packets_gen.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Schema describes the protocol versions & packets to generate
+type Schema struct {
+	Versions []*Version `json:"versions"`
+	Packets  []*Packet  `json:"packets"`
+}
+
+// Version is a client version that packets are generated for
+type Version struct {
+	// Name of the version struct, e.g. "b20120812"
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+
+	// Parent is the version struct that will be embedded. If it's empty,
+	// the version struct already exists and will only be extended.
+	Parent string `json:"parent"`
+
+	// Remaps contains packet ids that are sent with a different id,
+	// e.g. {"BanchoHandleIrcJoin": 11}
+	Remaps map[string]int `json:"remaps"`
+
+	// Shifts contains ranges of packet ids that are offset on the wire
+	Shifts []*Shift `json:"shifts"`
+
+	Added   []*Packet `json:"-"`
+	Removed []*Packet `json:"-"`
+}
+
+// Shift moves the ids from Min to Max (inclusive, as sent on the wire) by Offset
+type Shift struct {
+	Min    int `json:"min"`
+	Max    int `json:"max"`
+	Offset int `json:"offset"`
+}
+
+// Packet describes the payload of a packet, which is either
+// empty, a single value or a struct with "bancho" tags
+type Packet struct {
+	// Id is the name of the packet id constant, e.g. "BanchoAnnounce"
+	Id string `json:"id"`
+
+	// Method is the name of the writer method, e.g. "WriteAnnouncement"
+	Method string `json:"method"`
+
+	// Type is the wire type of the payload, or the name of a struct
+	Type string `json:"type"`
+
+	// Arg is the name of the writer argument
+	Arg string `json:"arg"`
+
+	// Since & Until are the range of versions that support the packet
+	Since int `json:"since"`
+	Until int `json:"until"`
+}
+
+// IdMin returns the lowest id of the range, after it was shifted
+func (shift *Shift) IdMin() int {
+	return shift.Min + shift.Offset
+}
+
+// IdMax returns the highest id of the range, after it was shifted
+func (shift *Shift) IdMax() int {
+	return shift.Max + shift.Offset
+}
+
+// Apply returns the expression that converts a wire id to a packet id
+func (shift *Shift) Apply() string {
+	return offsetExpression(shift.Offset)
+}
+
+// Revert returns the expression that converts a packet id to a wire id
+func (shift *Shift) Revert() string {
+	return offsetExpression(-shift.Offset)
+}
+
+func offsetExpression(offset int) string {
+	if offset < 0 {
+		return fmt.Sprintf("packetId - %d", -offset)
+	}
+	return fmt.Sprintf("packetId + %d", offset)
+}
+
+type wireType struct {
+	goType string
+	write  string
+	read   string
+
+	// sample is the value used in the conformance tests
+	sample string
+}
+
+var wireTypes = map[string]wireType{
+	"uint8":     {"uint8", "writeUint8", "readUint8", "200"},
+	"int8":      {"int8", "writeInt8", "readInt8", "-100"},
+	"uint16":    {"uint16", "writeUint16", "readUint16", "60000"},
+	"int16":     {"int16", "writeInt16", "readInt16", "-30000"},
+	"uint32":    {"uint32", "writeUint32", "readUint32", "4000000000"},
+	"int32":     {"int32", "writeInt32", "readInt32", "-2000000000"},
+	"uint64":    {"uint64", "writeUint64", "readUint64", "18000000000000000000"},
+	"int64":     {"int64", "writeInt64", "readInt64", "-9000000000000000000"},
+	"float32":   {"float32", "writeFloat32", "readFloat32", "0.75"},
+	"float64":   {"float64", "writeFloat64", "readFloat64", "0.9875"},
+	"bool":      {"bool", "writeBoolean", "readBoolean", "true"},
+	"string":    {"string", "writeString", "readString", `"chio"`},
+	"intlist16": {"[]int32", "writeIntList16", "readIntList16", "[]int32{2, 3, 1000}"},
+	"intlist32": {"[]int32", "writeIntList32", "readIntList32", "[]int32{2, 3, 1000}"},
+	"boollist":  {"[]bool", "writeBoolList", "readBoolList", "[]bool{true, false, true, true, false, false, true, false}"},
+}
+
+func (packet *Packet) IsEmpty() bool {
+	return packet.Type == ""
+}
+
+func (packet *Packet) IsStruct() bool {
+	_, ok := wireTypes[packet.Type]
+	return !packet.IsEmpty() && !ok
+}
+
+func (packet *Packet) GoType() string {
+	if t, ok := wireTypes[packet.Type]; ok {
+		return t.goType
+	}
+	return packet.Type
+}
+
+func (packet *Packet) WriteFunc() string {
+	return wireTypes[packet.Type].write
+}
+
+func (packet *Packet) ReadFunc() string {
+	return wireTypes[packet.Type].read
+}
+
+// Sample returns the value that the conformance test sends
+func (packet *Packet) Sample() string {
+	if t, ok := wireTypes[packet.Type]; ok {
+		return t.sample
+	}
+	return packet.Type + "{}"
+}
+
+// Expected returns the value that the conformance test expects to read
+func (packet *Packet) Expected() string {
+	if packet.IsEmpty() {
+		return "nil"
+	}
+	if packet.IsStruct() {
+		return "&" + packet.ArgName()
+	}
+	return packet.ArgName()
+}
+
+func (packet *Packet) ArgName() string {
+	if packet.Arg != "" {
+		return packet.Arg
+	}
+	return "value"
+}
+
+func (version *Version) Constructor() string {
+	return constructorName(version.Name)
+}
+
+func (version *Version) ParentConstructor() string {
+	return constructorName(version.Parent)
+}
+
+func (version *Version) TestName() string {
+	return strings.ToUpper(version.Name[:1]) + version.Name[1:]
+}
+
+func (version *Version) InitFunc() string {
+	return "init" + strings.ToUpper(version.Name[:1]) + version.Name[1:] + "Packets"
+}
+
+func constructorName(name string) string {
+	return "new" + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// RemapIds returns the remapped packet ids in a stable order
+func (version *Version) RemapIds() []string {
+	ids := make([]string, 0, len(version.Remaps))
+	for id := range version.Remaps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (version *Version) HasIdConversion() bool {
+	return len(version.Remaps) > 0 || len(version.Shifts) > 0
+}
+
+func main() {
+	schemaPath := flag.String("schema", "protocol.json", "path to the protocol schema")
+	outputPath := flag.String("output", "packets_gen.go", "path of the generated file")
+	testPath := flag.String("test", "", "path of the generated tests (default: output with a _test suffix)")
+	flag.Parse()
+
+	if *testPath == "" {
+		*testPath = strings.TrimSuffix(*outputPath, ".go") + "_test.go"
+	}
+
+	data, err := os.ReadFile(*schemaPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	schema := &Schema{}
+	err = json.Unmarshal(data, schema)
+	if err != nil {
+		log.Fatalf("invalid schema: %v", err)
+	}
+
+	err = schema.resolve()
+	if err != nil {
+		log.Fatalf("invalid schema: %v", err)
+	}
+
+	source, err := generate(fileTemplate, schema)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tests, err := generate(testTemplate, schema)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = os.WriteFile(*outputPath, source, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = os.WriteFile(*testPath, tests, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// resolve assigns every packet to the first generated version that
+// supports it, and to the first version that no longer supports it
+func (schema *Schema) resolve() error {
+	if len(schema.Versions) == 0 {
+		return fmt.Errorf("no versions were declared")
+	}
+
+	sort.Slice(schema.Versions, func(i, j int) bool {
+		return schema.Versions[i].Version < schema.Versions[j].Version
+	})
+
+	for _, version := range schema.Versions {
+		for _, shift := range version.Shifts {
+			if shift.Min > shift.Max || shift.IdMin() < 0 || shift.IdMax() > 0xFFFF {
+				return fmt.Errorf("version '%s' has an invalid id shift", version.Name)
+			}
+		}
+	}
+
+	for _, packet := range schema.Packets {
+		if packet.Id == "" || packet.Method == "" {
+			return fmt.Errorf("packet is missing an id or method")
+		}
+		if packet.Until != 0 && packet.Until <= packet.Since {
+			return fmt.Errorf("packet '%s' has an empty version range", packet.Id)
+		}
+
+		added := schema.versionFrom(packet.Since)
+		if added == nil {
+			return fmt.Errorf("no version was declared for packet '%s'", packet.Id)
+		}
+		added.Added = append(added.Added, packet)
+
+		if packet.Until == 0 {
+			continue
+		}
+		removed := schema.versionFrom(packet.Until)
+		if removed == added {
+			return fmt.Errorf("packet '%s' is not supported by any declared version", packet.Id)
+		}
+		if removed != nil {
+			removed.Removed = append(removed.Removed, packet)
+		}
+	}
+
+	return nil
+}
+
+func (schema *Schema) NeedsBytes() bool {
+	for _, packet := range schema.Packets {
+		if !packet.IsEmpty() {
+			return true
+		}
+	}
+	return false
+}
+
+func (schema *Schema) NeedsIo() bool {
+	return len(schema.Packets) > 0
+}
+
+func (schema *Schema) NeedsSlices() bool {
+	for _, version := range schema.Versions {
+		if len(version.Removed) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (schema *Schema) HasTests() bool {
+	for _, version := range schema.Versions {
+		if version.HasIdConversion() {
+			return true
+		}
+	}
+	return len(schema.Packets) > 0
+}
+
+// versionFrom returns the first declared version that is equal or newer
+func (schema *Schema) versionFrom(version int) *Version {
+	for _, v := range schema.Versions {
+		if v.Version >= version {
+			return v
+		}
+	}
+	return nil
+}
+
+func generate(tmpl *template.Template, schema *Schema) ([]byte, error) {
+	output := bytes.NewBuffer([]byte{})
+	err := tmpl.Execute(output, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := format.Source(output.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %w\n%s", err, output.String())
+	}
+	return source, nil
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by chiogen. DO NOT EDIT.
+
+package chio
+
+import (
+	{{- if .NeedsBytes}}
+	"bytes"
+	{{- end}}
+	{{- if .NeedsIo}}
+	"io"
+	{{- end}}
+	{{- if .NeedsSlices}}
+	"slices"
+	{{- end}}
+)
+
+{{range $version := .Versions}}
+{{- if $version.Parent}}
+type {{$version.Name}} struct {
+	*{{$version.Parent}}
+}
+
+func (client *{{$version.Name}}) Clone() BanchoIO {
+	clone := client.clone()
+	clone.BanchoIO = clone
+	return clone
+}
+
+func (client *{{$version.Name}}) clone() *{{$version.Name}} {
+	return &{{$version.Name}}{client.{{$version.Parent}}.clone()}
+}
+
+func {{$version.Constructor}}() *{{$version.Name}} {
+	client := &{{$version.Name}}{ {{- $version.ParentConstructor}}()}
+	client.BanchoIO = client
+	client.version = {{$version.Version}}
+	{{$version.InitFunc}}(client)
+	return client
+}
+
+func init() {
+	RegisterClient({{$version.Version}}, {{$version.Constructor}}())
+}
+{{end}}
+{{- if $version.HasIdConversion}}
+func (client *{{$version.Name}}) ConvertInputPacketId(packetId uint16) uint16 {
+	switch packetId {
+	{{- range $id := $version.RemapIds}}
+	case {{index $version.Remaps $id}}:
+		return {{$id}}
+	{{- end}}
+	}
+	{{- range $version.Shifts}}
+	if packetId >= {{.Min}} && packetId <= {{.Max}} {
+		return {{.Apply}}
+	}
+	{{- end}}
+	return packetId
+}
+
+func (client *{{$version.Name}}) ConvertOutputPacketId(packetId uint16) uint16 {
+	switch packetId {
+	{{- range $id := $version.RemapIds}}
+	case {{$id}}:
+		return {{index $version.Remaps $id}}
+	{{- end}}
+	}
+	{{- range $version.Shifts}}
+	if packetId >= {{.IdMin}} && packetId <= {{.IdMax}} {
+		return {{.Revert}}
+	}
+	{{- end}}
+	return packetId
+}
+{{end}}
+{{- range $version.Added}}
+func (client *{{$version.Name}}) {{.Method}}(stream io.Writer{{if not .IsEmpty}}, {{.ArgName}} {{.GoType}}{{end}}) error {
+	{{- if .IsEmpty}}
+	return client.BanchoIO.WritePacket(stream, {{.Id}}, []byte{})
+	{{- else if .IsStruct}}
+	writer := bytes.NewBuffer([]byte{})
+	err := MarshalTo(writer, &{{.ArgName}}, client.version)
+	if err != nil {
+		return err
+	}
+	return client.BanchoIO.WritePacket(stream, {{.Id}}, writer.Bytes())
+	{{- else}}
+	writer := bytes.NewBuffer([]byte{})
+	err := {{.WriteFunc}}(writer, {{.ArgName}})
+	if err != nil {
+		return err
+	}
+	return client.BanchoIO.WritePacket(stream, {{.Id}}, writer.Bytes())
+	{{- end}}
+}
+{{end}}
+{{- range $version.Removed}}
+func (client *{{$version.Name}}) {{.Method}}(stream io.Writer{{if not .IsEmpty}}, {{.ArgName}} {{.GoType}}{{end}}) error {
+	return nil
+}
+{{end}}
+// {{$version.InitFunc}} registers the generated packets of {{$version.Name}}
+func {{$version.InitFunc}}(client *{{$version.Name}}) {
+	{{- range $version.Added}}
+	{{- if .IsStruct}}
+	client.readers[{{.Id}}] = func(c BanchoIO, reader io.Reader) (any, error) {
+		value := &{{.GoType}}{}
+		return value, UnmarshalFrom(reader, value, c.Version())
+	}
+	{{- else if not .IsEmpty}}
+	client.readers[{{.Id}}] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return {{.ReadFunc}}(reader)
+	}
+	{{- end}}
+	{{- end}}
+	{{- range $version.Removed}}
+	delete(client.readers, {{.Id}})
+	{{- end}}
+	{{- if $version.Added}}
+
+	client.supportedPackets = append(client.supportedPackets,
+	{{- range $version.Added}}
+		{{.Id}},
+	{{- end}}
+	)
+	{{- end}}
+	{{- if $version.Removed}}
+
+	client.supportedPackets = slices.DeleteFunc(slices.Clone(client.supportedPackets), func(id uint16) bool {
+		return {{range $i, $p := $version.Removed}}{{if $i}} || {{end}}id == {{$p.Id}}{{end}}
+	})
+	{{- end}}
+}
+{{end}}
+`))
+
+var testTemplate = template.Must(template.New("test").Parse(`// Code generated by chiogen. DO NOT EDIT.
+
+package chio
+{{if .HasTests}}
+import (
+	"bytes"
+	{{- if .NeedsIo}}
+	"reflect"
+	{{- end}}
+	"testing"
+)
+{{end}}
+{{- range $version := .Versions}}
+{{- range $version.Added}}
+func TestGenerated{{$version.TestName}}{{.Method}}(t *testing.T) {
+	client := {{$version.Constructor}}()
+	stream := bytes.NewBuffer([]byte{})
+	{{- if not .IsEmpty}}
+	{{.ArgName}} := {{.GoType}}({{.Sample}})
+	{{- end}}
+
+	err := client.{{.Method}}(stream{{if not .IsEmpty}}, {{.ArgName}}{{end}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Id != {{.Id}} {
+		t.Fatalf("expected packet %d, got %d", {{.Id}}, packet.Id)
+	}
+	if !reflect.DeepEqual(packet.Data, {{.Expected}}) {
+		t.Fatalf("expected %#v, got %#v", {{.Expected}}, packet.Data)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("%d bytes left after reading the packet", stream.Len())
+	}
+}
+{{end}}
+{{- range $version.Removed}}
+func TestGenerated{{$version.TestName}}{{.Method}}Removed(t *testing.T) {
+	client := {{$version.Constructor}}()
+	stream := bytes.NewBuffer([]byte{})
+
+	err := client.{{.Method}}(stream{{if not .IsEmpty}}, {{.Sample}}{{end}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("expected no data to be written, got %d bytes", stream.Len())
+	}
+	if client.ImplementsPacket({{.Id}}) {
+		t.Fatal("expected the packet to be unsupported")
+	}
+}
+{{end}}
+{{- if $version.HasIdConversion}}
+func TestGenerated{{$version.TestName}}PacketIds(t *testing.T) {
+	tests := []struct {
+		packetId uint16
+		wireId   uint16
+	}{
+		{{- range $id := $version.RemapIds}}
+		{ {{- $id}}, {{index $version.Remaps $id}}},
+		{{- end}}
+		{{- range $version.Shifts}}
+		{ {{- .IdMin}}, {{.Min}}},
+		{ {{- .IdMax}}, {{.Max}}},
+		{{- end}}
+	}
+
+	for _, test := range tests {
+		var client BanchoIO = {{$version.Constructor}}()
+		stream := bytes.NewBuffer([]byte{})
+
+		err := client.WritePacket(stream, test.packetId, []byte{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		wireId := uint16(stream.Bytes()[0]) | uint16(stream.Bytes()[1])<<8
+		if wireId != test.wireId {
+			t.Errorf("packet %d: expected wire id %d, got %d", test.packetId, test.wireId, wireId)
+		}
+
+		header, err := client.ReadPacketHeader(stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Id != test.packetId {
+			t.Errorf("wire id %d: expected packet %d, got %d", test.wireId, test.packetId, header.Id)
+		}
+	}
+}
+{{end}}
+{{- end}}
+`))
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadSchema(t *testing.T, paths ...string) *Schema {
+	t.Helper()
+	schema := &Schema{}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		part := &Schema{}
+		if err := json.Unmarshal(data, part); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		schema.Versions = append(schema.Versions, part.Versions...)
+		schema.Packets = append(schema.Packets, part.Packets...)
+	}
+
+	if err := schema.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestResolve(t *testing.T) {
+	schema := loadSchema(t, "../../protocol.json", "testdata/remapped.json")
+	base, remapped := schema.Versions[0], schema.Versions[1]
+
+	if base.Name != "b20120812" || remapped.Name != "remapped" {
+		t.Fatalf("expected versions to be sorted, got %s & %s", base.Name, remapped.Name)
+	}
+	if len(remapped.Added) != 3 || len(remapped.Removed) != 1 {
+		t.Fatalf("expected 3 added & 1 removed packet, got %d & %d", len(remapped.Added), len(remapped.Removed))
+	}
+	if remapped.Removed[0].Id != "BanchoTitleUpdate" {
+		t.Fatalf("expected BanchoTitleUpdate to be removed, got %s", remapped.Removed[0].Id)
+	}
+}
+
+func TestResolveInvalid(t *testing.T) {
+	schemas := []*Schema{
+		{},
+		{Versions: []*Version{{Name: "b1", Version: 1}}, Packets: []*Packet{{Id: "BanchoPing"}}},
+		{Versions: []*Version{{Name: "b1", Version: 1}}, Packets: []*Packet{{Id: "BanchoPing", Method: "WritePing", Since: 2}}},
+		{Versions: []*Version{{Name: "b1", Version: 1}}, Packets: []*Packet{{Id: "BanchoPing", Method: "WritePing", Since: 1, Until: 1}}},
+		{Versions: []*Version{{Name: "b1", Version: 1, Shifts: []*Shift{{Min: 10, Max: 20, Offset: -11}}}}},
+	}
+
+	for i, schema := range schemas {
+		if err := schema.resolve(); err == nil {
+			t.Errorf("schema %d: expected an error", i)
+		}
+	}
+}
+
+// TestGeneratedPackage generates the protocol together with a remapped
+// version into a copy of chio, and runs the generated conformance tests
+func TestGeneratedPackage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping build of the generated package in short mode")
+	}
+
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not available")
+	}
+
+	schema := loadSchema(t, "../../protocol.json", "testdata/remapped.json")
+	source, err := generate(fileTemplate, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests, err := generate(testTemplate, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files, err := os.ReadDir("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, "packets_gen") {
+			continue
+		}
+		if strings.HasSuffix(name, "_test.go") || !strings.HasSuffix(name, ".go") && !strings.HasPrefix(name, "go.") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join("../..", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "packets_gen.go"), source, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "packets_gen_test.go"), tests, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(gobin, "test", "-count=1", "-run", "^TestGenerated", "-v", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated tests failed: %v\n%s", err, output)
+	}
+
+	for _, name := range []string{"TestGeneratedRemappedWriteGeneratedChannel", "TestGeneratedRemappedWriteGeneratedFlags", "TestGeneratedRemappedWriteGeneratedTitleRemoved", "TestGeneratedRemappedPacketIds"} {
+		if !strings.Contains(string(output), "--- PASS: "+name) {
+			t.Errorf("expected %s to run\n%s", name, output)
+		}
+	}
+}
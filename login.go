@@ -0,0 +1,152 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxLoginRequestSize is the limit for the size of login requests
+const maxLoginRequestSize int = 64 * 1024
+
+// LoginRequest contains the data that the client sends on login
+type LoginRequest struct {
+	Username     string
+	PasswordHash string
+	ClientData   string
+
+	// The fields below are parsed from the client data.
+	// Older clients will only send some of them.
+	Version       string
+	UtcOffset     int
+	DisplayCity   bool
+	ClientHashes  *ClientHashes
+	FriendOnlyDms bool
+}
+
+// ClientHashes contains the hardware & executable hashes
+// that are sent inside the client data
+type ClientHashes struct {
+	ExecutableHash string
+	Adapters       []string
+	AdaptersHash   string
+	UninstallId    string
+	DiskSignature  string
+}
+
+// ClientVersion parses the version that was sent by the client
+func (request *LoginRequest) ClientVersion() (ClientVersion, error) {
+	return ParseClientVersion(request.Version)
+}
+
+// ClientInterface returns the BanchoIO that matches the client version
+func (request *LoginRequest) ClientInterface() (BanchoIO, error) {
+	version, err := request.ClientVersion()
+	if err != nil {
+		return nil, err
+	}
+	return version.ClientInterface(), nil
+}
+
+// ParseLoginRequest parses the login request body, which
+// consists of the username, password hash and client data,
+// separated by newlines
+func ParseLoginRequest(body []byte) (*LoginRequest, error) {
+	lines := strings.Split(strings.TrimRight(string(body), "\r\n"), "\n")
+
+	if len(lines) < 3 {
+		return nil, errors.New("invalid login request")
+	}
+
+	request := &LoginRequest{
+		Username:     strings.TrimSpace(lines[0]),
+		PasswordHash: strings.TrimSpace(lines[1]),
+		ClientData:   strings.TrimSpace(lines[2]),
+	}
+
+	err := request.parseClientData()
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// ReadLoginRequest reads the login request from a tcp stream, without
+// consuming any of the packets that the client sends afterwards
+func ReadLoginRequest(stream io.Reader) (*LoginRequest, error) {
+	body := bytes.NewBuffer([]byte{})
+	lines := 0
+
+	for lines < 3 {
+		b, err := readUint8(stream)
+		if err != nil {
+			return nil, err
+		}
+
+		if b == '\n' {
+			lines++
+		}
+
+		body.WriteByte(b)
+
+		if body.Len() > maxLoginRequestSize {
+			return nil, errors.New("login request is too large")
+		}
+	}
+
+	return ParseLoginRequest(body.Bytes())
+}
+
+// parseClientData parses the client data, which is formatted as
+// "version|utc_offset|display_city|client_hashes|friend_only_dms"
+func (request *LoginRequest) parseClientData() (err error) {
+	fields := strings.Split(request.ClientData, "|")
+	request.Version = fields[0]
+
+	if len(fields) > 1 {
+		request.UtcOffset, err = strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("invalid utc offset '%s'", fields[1])
+		}
+	}
+
+	if len(fields) > 2 {
+		request.DisplayCity = fields[2] == "1"
+	}
+
+	if len(fields) > 3 {
+		request.ClientHashes = parseClientHashes(fields[3])
+	}
+
+	if len(fields) > 4 {
+		request.FriendOnlyDms = fields[4] == "1"
+	}
+
+	return nil
+}
+
+// parseClientHashes parses the client hashes, which are formatted as
+// "executable_hash:adapters:adapters_hash:uninstall_id:disk_signature:"
+func parseClientHashes(hashes string) *ClientHashes {
+	fields := strings.Split(hashes, ":")
+	result := &ClientHashes{ExecutableHash: fields[0]}
+
+	if len(fields) > 1 && fields[1] != "" {
+		result.Adapters = strings.Split(strings.TrimSuffix(fields[1], "."), ".")
+	}
+	if len(fields) > 2 {
+		result.AdaptersHash = fields[2]
+	}
+	if len(fields) > 3 {
+		result.UninstallId = fields[3]
+	}
+	if len(fields) > 4 {
+		result.DiskSignature = fields[4]
+	}
+
+	return result
+}
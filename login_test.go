@@ -0,0 +1,96 @@
+package chio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseLoginRequest(t *testing.T) {
+	request, err := ParseLoginRequest([]byte(testLoginRequest))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &LoginRequest{
+		Username:     "peppy",
+		PasswordHash: "5f4dcc3b5aa765d61d8327deb882cf99",
+		ClientData:   "b20130815|1|0|e7a8c0b3:00-1A-2B-3C-4D-5E.:6f1ed002:d41d8cd9:c4ca4238:|0",
+		Version:      "b20130815",
+		UtcOffset:    1,
+		ClientHashes: &ClientHashes{
+			ExecutableHash: "e7a8c0b3",
+			Adapters:       []string{"00-1A-2B-3C-4D-5E"},
+			AdaptersHash:   "6f1ed002",
+			UninstallId:    "d41d8cd9",
+			DiskSignature:  "c4ca4238",
+		},
+	}
+
+	if !reflect.DeepEqual(request, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, request)
+	}
+}
+
+func TestParseLoginRequestOldClient(t *testing.T) {
+	// Older clients only send their version
+	request, err := ParseLoginRequest([]byte("peppy\r\n5f4dcc3b5aa765d61d8327deb882cf99\r\nb282\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if request.Version != "b282" || request.ClientHashes != nil {
+		t.Fatalf("unexpected client data: %#v", request)
+	}
+
+	client, err := request.ClientInterface()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Version() != 282 {
+		t.Fatalf("expected client version 282, got %d", client.Version())
+	}
+}
+
+func TestParseLoginRequestInvalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"peppy\n5f4dcc3b5aa765d61d8327deb882cf99\n",
+		"peppy\n5f4dcc3b5aa765d61d8327deb882cf99\nb282|utc\n",
+	}
+
+	for _, body := range invalid {
+		if _, err := ParseLoginRequest([]byte(body)); err == nil {
+			t.Errorf("expected an error for %q", body)
+		}
+	}
+
+	request, err := ParseLoginRequest([]byte("peppy\nhash\nosu!\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := request.ClientInterface(); err == nil {
+		t.Fatal("expected an error for an invalid version")
+	}
+}
+
+func TestReadLoginRequest(t *testing.T) {
+	client := newTestClient(t, 282)
+	stream := bytes.NewBufferString(testLoginRequest)
+	client.WriteOsuPong(stream)
+
+	request, err := ReadLoginRequest(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Username != "peppy" {
+		t.Fatalf("expected username peppy, got %s", request.Username)
+	}
+
+	// The packets after the login request should not be consumed
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, OsuPong, nil)
+}
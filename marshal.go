@@ -0,0 +1,598 @@
+package chio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Marshal & Unmarshal encode structs by using their "bancho" struct tags,
+// which describe how a field is sent in each version of the protocol.
+// Only fields with a tag will be encoded, in the order they are declared,
+// unless the fields specify their order.
+//
+// A tag consists of the wire type, followed by optional options:
+//
+//	Count int32  `bancho:"int16,until=300;int32,since=300"`
+//	Text  string `bancho:"string,if=IsKnown"`
+//
+//   - since: the first version that contains the field
+//   - until: the first version that no longer contains the field
+//   - if: a method or bool field of the struct that tells whether the field is present
+//   - get: a method of the struct that returns the value to encode, instead of the field
+//   - order: the position of the field, for structs that aren't declared in wire order.
+//     Either all or none of the tagged fields of a struct have to use it.
+//
+// Multiple encodings for different versions are separated by a semicolon,
+// and the first one that matches the version will be used.
+//
+// The supported wire types are uint8, int8, uint16, int16, uint32, int32,
+// uint64, int64, float32, float64, bool, string, intlist16, intlist32,
+// boollist and struct, where struct encodes a nested (pointer to a) struct.
+// Button states of replay frames can be encoded as legacybuttons, which are
+// the two mouse buttons of b282, or as buttonstate, followed by the legacy byte.
+
+// fieldEncoding describes how a field is encoded within a range of versions
+type fieldEncoding struct {
+	wireType  string
+	since     int
+	until     int
+	condition string
+	getter    string
+}
+
+// matches checks if the encoding is used in the given version
+func (encoding *fieldEncoding) matches(version int) bool {
+	if version < encoding.since {
+		return false
+	}
+	return encoding.until == 0 || version < encoding.until
+}
+
+// taggedField is a struct field that has a "bancho" tag
+type taggedField struct {
+	index     int
+	name      string
+	order     int
+	encodings []fieldEncoding
+}
+
+// encoding returns the encoding of the field for the given version, if there is one
+func (field *taggedField) encoding(version int) *fieldEncoding {
+	for i := range field.encodings {
+		if field.encodings[i].matches(version) {
+			return &field.encodings[i]
+		}
+	}
+	return nil
+}
+
+// structFields caches the tagged fields of every struct type that was encoded
+var structFields sync.Map
+
+// Marshal encodes a struct, or a pointer to one, for the given client version
+func Marshal(v any, version int) ([]byte, error) {
+	writer := bytes.NewBuffer([]byte{})
+	err := MarshalTo(writer, v, version)
+	if err != nil {
+		return nil, err
+	}
+	return writer.Bytes(), nil
+}
+
+// MarshalTo works like Marshal, but writes the encoded struct to the writer
+func MarshalTo(writer io.Writer, v any, version int) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return fmt.Errorf("cannot marshal nil '%T'", v)
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot marshal '%T', expected a struct", v)
+	}
+	return marshalStruct(writer, value, version)
+}
+
+// Unmarshal decodes the data into a pointer to a struct, for the given client version
+func Unmarshal(data []byte, v any, version int) error {
+	return UnmarshalFrom(bytes.NewReader(data), v, version)
+}
+
+// UnmarshalFrom works like Unmarshal, but reads the encoded struct from the reader
+func UnmarshalFrom(reader io.Reader, v any, version int) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal into '%T', expected a pointer to a struct", v)
+	}
+	return unmarshalStruct(reader, value.Elem(), version)
+}
+
+func marshalStruct(writer io.Writer, value reflect.Value, version int) error {
+	fields, err := getTaggedFields(value.Type())
+	if err != nil {
+		return err
+	}
+
+	if !value.CanAddr() {
+		// Conditions may use pointer receivers
+		addressable := reflect.New(value.Type()).Elem()
+		addressable.Set(value)
+		value = addressable
+	}
+
+	for _, field := range fields {
+		encoding := field.encoding(version)
+		if encoding == nil {
+			continue
+		}
+
+		present, err := checkCondition(value, encoding.condition)
+		if err != nil {
+			return err
+		}
+		if !present {
+			continue
+		}
+
+		fieldValue := value.Field(field.index)
+
+		if encoding.getter != "" {
+			fieldValue, err = callGetter(value, encoding.getter)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = writeValue(writer, encoding.wireType, fieldValue, version)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", value.Type().Name(), field.name, err)
+		}
+	}
+
+	return nil
+}
+
+func unmarshalStruct(reader io.Reader, value reflect.Value, version int) error {
+	fields, err := getTaggedFields(value.Type())
+	if err != nil {
+		return err
+	}
+
+	decoder := newFieldReader(reader, value.Type().Name())
+
+	for _, field := range fields {
+		encoding := field.encoding(version)
+		if encoding == nil {
+			continue
+		}
+
+		present, err := checkCondition(value, encoding.condition)
+		if err != nil {
+			return err
+		}
+		if !present {
+			continue
+		}
+
+		decoder.read(field.name, func(r io.Reader) error {
+			return readValue(r, encoding.wireType, value.Field(field.index), version)
+		})
+	}
+
+	return decoder.Err()
+}
+
+// checkCondition calls the condition method, or reads the condition
+// field of the struct, if there is one
+func checkCondition(value reflect.Value, condition string) (bool, error) {
+	if condition == "" {
+		return true, nil
+	}
+
+	if field := value.FieldByName(condition); field.IsValid() && field.Kind() == reflect.Bool {
+		return field.Bool(), nil
+	}
+
+	method := value.Addr().MethodByName(condition)
+	if !method.IsValid() {
+		return false, fmt.Errorf("condition '%s' is not a method of '%s'", condition, value.Type().Name())
+	}
+
+	result, ok := method.Interface().(func() bool)
+	if !ok {
+		return false, fmt.Errorf("condition '%s' of '%s' must return a bool", condition, value.Type().Name())
+	}
+
+	return result(), nil
+}
+
+// callGetter returns the value of a getter method of the struct
+func callGetter(value reflect.Value, getter string) (reflect.Value, error) {
+	method := value.Addr().MethodByName(getter)
+	if !method.IsValid() {
+		return reflect.Value{}, fmt.Errorf("getter '%s' is not a method of '%s'", getter, value.Type().Name())
+	}
+
+	if method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return reflect.Value{}, fmt.Errorf("getter '%s' of '%s' must return a single value", getter, value.Type().Name())
+	}
+
+	return method.Call(nil)[0], nil
+}
+
+func writeValue(writer io.Writer, wireType string, value reflect.Value, version int) error {
+	switch wireType {
+	case "uint8", "int8", "uint16", "int16", "uint32", "int32", "uint64", "int64":
+		v, err := integerValue(value)
+		if err != nil {
+			return err
+		}
+		return writeInteger(writer, wireType, v)
+	case "float32":
+		if !value.CanFloat() {
+			return fmt.Errorf("cannot encode '%s' as %s", value.Type(), wireType)
+		}
+		return writeFloat32(writer, float32(value.Float()))
+	case "float64":
+		if !value.CanFloat() {
+			return fmt.Errorf("cannot encode '%s' as %s", value.Type(), wireType)
+		}
+		return writeFloat64(writer, value.Float())
+	case "bool":
+		if value.Kind() != reflect.Bool {
+			return fmt.Errorf("cannot encode '%s' as %s", value.Type(), wireType)
+		}
+		return writeBoolean(writer, value.Bool())
+	case "string":
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("cannot encode '%s' as %s", value.Type(), wireType)
+		}
+		return writeString(writer, value.String())
+	case "intlist16", "intlist32":
+		list, ok := value.Interface().([]int32)
+		if !ok {
+			return fmt.Errorf("cannot encode '%s' as %s", value.Type(), wireType)
+		}
+		if wireType == "intlist16" {
+			return writeIntList16(writer, list)
+		}
+		return writeIntList32(writer, list)
+	case "boollist":
+		list, ok := value.Interface().([]bool)
+		if !ok {
+			return fmt.Errorf("cannot encode '%s' as %s", value.Type(), wireType)
+		}
+		return writeBoolList(writer, list)
+	case "legacybuttons", "buttonstate":
+		if value.Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot encode '%s' as %s", value.Type(), wireType)
+		}
+		return writeButtonState(writer, wireType, uint8(value.Uint()))
+	case "struct":
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				// Write the zero value of the struct instead
+				value = reflect.New(value.Type().Elem())
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return fmt.Errorf("cannot encode '%s' as %s", value.Type(), wireType)
+		}
+		return marshalStruct(writer, value, version)
+	}
+
+	return fmt.Errorf("unknown wire type '%s'", wireType)
+}
+
+func readValue(reader io.Reader, wireType string, value reflect.Value, version int) error {
+	switch wireType {
+	case "uint8", "int8", "uint16", "int16", "uint32", "int32", "uint64", "int64":
+		v, err := readInteger(reader, wireType)
+		if err != nil {
+			return err
+		}
+		return setInteger(value, v)
+	case "float32":
+		v, err := readFloat32(reader)
+		if err != nil {
+			return err
+		}
+		if !value.CanFloat() {
+			return fmt.Errorf("cannot decode %s into '%s'", wireType, value.Type())
+		}
+		value.SetFloat(float64(v))
+		return nil
+	case "float64":
+		v, err := readFloat64(reader)
+		if err != nil {
+			return err
+		}
+		if !value.CanFloat() {
+			return fmt.Errorf("cannot decode %s into '%s'", wireType, value.Type())
+		}
+		value.SetFloat(v)
+		return nil
+	case "bool":
+		v, err := readBoolean(reader)
+		if err != nil {
+			return err
+		}
+		if value.Kind() != reflect.Bool {
+			return fmt.Errorf("cannot decode %s into '%s'", wireType, value.Type())
+		}
+		value.SetBool(v)
+		return nil
+	case "string":
+		v, err := readString(reader)
+		if err != nil {
+			return err
+		}
+		if value.Kind() != reflect.String {
+			return fmt.Errorf("cannot decode %s into '%s'", wireType, value.Type())
+		}
+		value.SetString(v)
+		return nil
+	case "intlist16", "intlist32":
+		read := readIntList32
+		if wireType == "intlist16" {
+			read = readIntList16
+		}
+		v, err := read(reader)
+		if err != nil {
+			return err
+		}
+		return setList(value, wireType, v)
+	case "boollist":
+		v, err := readBoolList(reader)
+		if err != nil {
+			return err
+		}
+		return setList(value, wireType, v)
+	case "legacybuttons", "buttonstate":
+		v, err := readButtonState(reader, wireType)
+		if err != nil {
+			return err
+		}
+		if value.Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot decode %s into '%s'", wireType, value.Type())
+		}
+		value.SetUint(uint64(v))
+		return nil
+	case "struct":
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			return fmt.Errorf("cannot decode %s into '%s'", wireType, value.Type())
+		}
+		return unmarshalStruct(reader, value, version)
+	}
+
+	return fmt.Errorf("unknown wire type '%s'", wireType)
+}
+
+// integerValue returns the value of any integer field
+func integerValue(value reflect.Value) (int64, error) {
+	switch {
+	case value.CanInt():
+		return value.Int(), nil
+	case value.CanUint():
+		return int64(value.Uint()), nil
+	}
+	return 0, fmt.Errorf("cannot encode '%s' as an integer", value.Type())
+}
+
+// setInteger sets the value of any integer field, truncating it if necessary
+func setInteger(value reflect.Value, v int64) error {
+	switch {
+	case value.CanInt():
+		value.SetInt(v)
+	case value.CanUint():
+		value.SetUint(uint64(v))
+	default:
+		return fmt.Errorf("cannot decode an integer into '%s'", value.Type())
+	}
+	return nil
+}
+
+func setList[T any](value reflect.Value, wireType string, list []T) error {
+	v := reflect.ValueOf(list)
+	if !v.Type().AssignableTo(value.Type()) {
+		return fmt.Errorf("cannot decode %s into '%s'", wireType, value.Type())
+	}
+	value.Set(v)
+	return nil
+}
+
+func writeInteger(writer io.Writer, wireType string, v int64) error {
+	switch wireType {
+	case "uint8":
+		return writeUint8(writer, uint8(v))
+	case "int8":
+		return writeInt8(writer, int8(v))
+	case "uint16":
+		return writeUint16(writer, uint16(v))
+	case "int16":
+		return writeInt16(writer, int16(v))
+	case "uint32":
+		return writeUint32(writer, uint32(v))
+	case "int32":
+		return writeInt32(writer, int32(v))
+	case "uint64":
+		return writeUint64(writer, uint64(v))
+	default:
+		return writeInt64(writer, v)
+	}
+}
+
+func readInteger(reader io.Reader, wireType string) (int64, error) {
+	switch wireType {
+	case "uint8":
+		v, err := readUint8(reader)
+		return int64(v), err
+	case "int8":
+		v, err := readInt8(reader)
+		return int64(v), err
+	case "uint16":
+		v, err := readUint16(reader)
+		return int64(v), err
+	case "int16":
+		v, err := readInt16(reader)
+		return int64(v), err
+	case "uint32":
+		v, err := readUint32(reader)
+		return int64(v), err
+	case "int32":
+		v, err := readInt32(reader)
+		return int64(v), err
+	case "uint64":
+		v, err := readUint64(reader)
+		return int64(v), err
+	default:
+		return readInt64(reader)
+	}
+}
+
+func writeButtonState(writer io.Writer, wireType string, state uint8) error {
+	if wireType == "buttonstate" {
+		writeUint8(writer, state)
+		return writeUint8(writer, 0) // legacy button byte
+	}
+
+	// Only the mouse buttons are sent, see ConvertButtonState
+	leftMouse, rightMouse := legacyButtons(state)
+	writeBoolean(writer, leftMouse)
+	return writeBoolean(writer, rightMouse)
+}
+
+func readButtonState(reader io.Reader, wireType string) (uint8, error) {
+	if wireType == "buttonstate" {
+		state, err := readUint8(reader)
+		if err != nil {
+			return 0, err
+		}
+		_, err = readUint8(reader)
+		return state, err
+	}
+
+	leftMouse, err := readBoolean(reader)
+	if err != nil {
+		return 0, err
+	}
+	rightMouse, err := readBoolean(reader)
+	return buttonStateFromLegacy(leftMouse, rightMouse), err
+}
+
+// getTaggedFields returns the tagged fields of the struct type
+func getTaggedFields(structType reflect.Type) ([]taggedField, error) {
+	if cached, ok := structFields.Load(structType); ok {
+		return cached.([]taggedField), nil
+	}
+
+	fields := make([]taggedField, 0, structType.NumField())
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag, ok := field.Tag.Lookup("bancho")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		encodings, order, err := parseFieldTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bancho tag on '%s.%s': %w", structType.Name(), field.Name, err)
+		}
+
+		fields = append(fields, taggedField{index: i, name: field.Name, order: order, encodings: encodings})
+	}
+
+	err := sortTaggedFields(structType, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	structFields.Store(structType, fields)
+	return fields, nil
+}
+
+// sortTaggedFields sorts the fields by their order option, if they have one
+func sortTaggedFields(structType reflect.Type, fields []taggedField) error {
+	ordered := 0
+	for _, field := range fields {
+		if field.order > 0 {
+			ordered++
+		}
+	}
+
+	if ordered == 0 {
+		return nil
+	}
+	if ordered != len(fields) {
+		return fmt.Errorf("either all or none of the bancho tags on '%s' have to specify an order", structType.Name())
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].order < fields[j].order
+	})
+	return nil
+}
+
+// parseFieldTag parses a tag like "uint16,until=334;uint32,since=334",
+// and returns its encodings along with the order of the field
+func parseFieldTag(tag string) ([]fieldEncoding, int, error) {
+	var encodings []fieldEncoding
+	var order int
+
+	for _, clause := range strings.Split(tag, ";") {
+		options := strings.Split(strings.TrimSpace(clause), ",")
+		encoding := fieldEncoding{wireType: strings.TrimSpace(options[0])}
+
+		if encoding.wireType == "" {
+			return nil, 0, fmt.Errorf("missing wire type in '%s'", clause)
+		}
+
+		for _, option := range options[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+
+			switch key {
+			case "since", "until":
+				version, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, 0, fmt.Errorf("invalid version '%s'", value)
+				}
+				if key == "since" {
+					encoding.since = version
+				} else {
+					encoding.until = version
+				}
+			case "if":
+				encoding.condition = value
+			case "get":
+				encoding.getter = value
+			case "order":
+				var err error
+				order, err = strconv.Atoi(value)
+				if err != nil || order <= 0 {
+					return nil, 0, fmt.Errorf("invalid order '%s'", value)
+				}
+			default:
+				return nil, 0, fmt.Errorf("unknown option '%s'", key)
+			}
+		}
+
+		encodings = append(encodings, encoding)
+	}
+
+	return encodings, order, nil
+}
@@ -0,0 +1,184 @@
+package chio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// expectMarshal checks that Marshal encodes v exactly like a hand-written
+// writer, and that Unmarshal decodes it back into the same value
+func expectMarshal[T any](t *testing.T, v T, decoded T, version int, expected []byte) {
+	t.Helper()
+
+	data, err := Marshal(&v, version)
+	if err != nil {
+		t.Fatalf("b%d: failed to marshal %T: %v", version, v, err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("b%d: %T: expected %v, got %v", version, v, expected, data)
+	}
+
+	var result T
+	if err := Unmarshal(data, &result, version); err != nil {
+		t.Fatalf("b%d: failed to unmarshal %T: %v", version, v, err)
+	}
+	if !reflect.DeepEqual(result, decoded) {
+		t.Fatalf("b%d: expected %#v, got %#v", version, decoded, result)
+	}
+}
+
+func TestMarshalUserStatus(t *testing.T) {
+	statuses := []UserStatus{
+		{Action: StatusPlaying, Text: "Playing", BeatmapChecksum: "a5b99395a42bd55bc5eb1d2411cbdf8b", Mods: 72, Mode: 1, BeatmapId: 75},
+		{Action: StatusUnknown},
+		{Action: StatusUnknown, UpdateStats: true},
+	}
+
+	for _, status := range statuses {
+		expected := bytes.NewBuffer([]byte{})
+		newB282().WriteStatus(expected, &status)
+
+		// The stats update is sent as the action, and the mode & beatmap id are not sent
+		decoded := UserStatus{
+			Action:          status.LegacyAction(),
+			Text:            status.Text,
+			Mods:            status.Mods,
+			BeatmapChecksum: status.BeatmapChecksum,
+		}
+		expectMarshal(t, status, decoded, 282, expected.Bytes())
+
+		expected.Reset()
+		writeUint8(expected, status.Action)
+		writeString(expected, status.Text)
+		writeString(expected, status.BeatmapChecksum)
+		writeUint32(expected, status.Mods)
+		writeUint8(expected, status.Mode)
+		writeInt32(expected, status.BeatmapId)
+
+		decoded = status
+		decoded.UpdateStats = false
+		expectMarshal(t, status, decoded, 20121223, expected.Bytes())
+	}
+}
+
+func TestMarshalUserStats(t *testing.T) {
+	info := UserInfo{
+		Id:       2,
+		Name:     "peppy",
+		Presence: &UserPresence{},
+		Status:   &UserStatus{Action: StatusIdle},
+		Stats:    &UserStats{Rank: 1, Rscore: 5000000, Tscore: 9000000, Accuracy: 0.5, Playcount: 120, PP: 4000},
+	}
+
+	// The stats are sent after the user id & name
+	expected := bytes.NewBuffer([]byte{})
+	newB282().WriteStats(expected, info)
+	offset := 4 + 1 + 1 + len(info.Name)
+	length := 8 + 8 + 4 + 8 + 4
+
+	stats := *info.Stats
+	stats.PP = 0
+	expectMarshal(t, *info.Stats, stats, 282, expected.Bytes()[offset:offset+length])
+
+	// The stats are sent after the user id, completeness & status
+	expected.Reset()
+	newB20120812().WriteStats(expected, info)
+	status, _ := Marshal(info.Status, 20120812)
+	offset = 4 + 1 + len(status)
+
+	stats.Accuracy = float64(float32(stats.Accuracy))
+	expectMarshal(t, *info.Stats, stats, 20120812, expected.Bytes()[offset:offset+length-4])
+
+	// The stats are sent after the user id & status
+	expected.Reset()
+	newB20121223().WriteStats(expected, info)
+	status, _ = Marshal(info.Status, 20121223)
+	offset = 4 + len(status)
+
+	expectMarshal(t, *info.Stats, *info.Stats, 20121223, expected.Bytes()[offset:])
+}
+
+func TestMarshalReplayFrame(t *testing.T) {
+	frame := ReplayFrame{ButtonState: ButtonStateLeft2 | ButtonStateSmoke, MouseX: 256, MouseY: 192, Time: 1000}
+	bundle := ReplayFrameBundle{Frames: []*ReplayFrame{&frame}}
+
+	// The frames are sent after the frame count
+	expected := bytes.NewBuffer([]byte{})
+	newB282().WriteFrameBundle(expected, bundle)
+
+	legacyFrame := frame
+	legacyFrame.ButtonState = ButtonStateLeft1
+	expectMarshal(t, frame, legacyFrame, 282, expected.Bytes()[2:len(expected.Bytes())-1])
+
+	// The frames are sent after the extra int & frame count
+	expected.Reset()
+	newB20120812().WriteFrameBundle(expected, bundle)
+	expectMarshal(t, frame, frame, 20120812, expected.Bytes()[6:len(expected.Bytes())-1])
+}
+
+func TestMarshalScoreFrame(t *testing.T) {
+	frame := newTestScoreFrame()
+	frame.TagByte = 4
+
+	expected := bytes.NewBuffer([]byte{})
+	newB323().WriteScoreFrame(expected, frame)
+
+	decoded := frame
+	decoded.TagByte = 0
+	expectMarshal(t, frame, decoded, 323, expected.Bytes())
+
+	expected.Reset()
+	newB20120812().WriteScoreFrame(expected, frame)
+	expectMarshal(t, frame, frame, 20120812, expected.Bytes())
+
+	// The ScoreV2 flag is always sent, followed by the portions if it's enabled
+	expectMarshal(t, frame, frame, 20160403, append(expected.Bytes(), 0))
+
+	scoreV2 := frame
+	scoreV2.ScoreV2 = true
+	scoreV2.ComboPortion = 0.75
+	scoreV2.BonusPortion = 0.125
+
+	writeBoolean(expected, true)
+	writeFloat64(expected, scoreV2.ComboPortion)
+	writeFloat64(expected, scoreV2.BonusPortion)
+	expectMarshal(t, scoreV2, scoreV2, 20160403, expected.Bytes())
+}
+
+func TestMarshalChannel(t *testing.T) {
+	channel := Channel{Name: "#osu", Topic: "General discussion", UserCount: 128}
+
+	expected := bytes.NewBuffer([]byte{})
+	writeString(expected, channel.Name)
+	writeString(expected, channel.Topic)
+	writeInt16(expected, channel.UserCount)
+
+	expectMarshal(t, channel, channel, 294, expected.Bytes())
+	expectMarshal(t, channel, Channel{}, 282, nil)
+}
+
+func TestMarshalMatchJoin(t *testing.T) {
+	join := MatchJoin{MatchId: 300, Password: "secret"}
+
+	expected := bytes.NewBuffer([]byte{})
+	newB298().WriteOsuMatchJoin(expected, join)
+
+	// Passwords are not sent yet, and the payload starts after the header
+	packet, err := newB298().ReadRawPacket(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectMarshal(t, join, MatchJoin{MatchId: 300}, 298, packet.Payload)
+}
+
+func TestMarshalInvalidOrder(t *testing.T) {
+	type invalid struct {
+		First  int32 `bancho:"int32,order=2"`
+		Second int32 `bancho:"int32"`
+	}
+
+	if _, err := Marshal(invalid{}, 282); err == nil {
+		t.Fatal("expected an error for a partially ordered struct")
+	}
+}
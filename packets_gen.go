@@ -0,0 +1,85 @@
+// Code generated by chiogen. DO NOT EDIT.
+
+package chio
+
+import (
+	"bytes"
+	"io"
+)
+
+func (client *b20120812) WriteGetAttention(stream io.Writer) error {
+	return client.BanchoIO.WritePacket(stream, BanchoGetAttention, []byte{})
+}
+
+func (client *b20120812) WriteAnnouncement(stream io.Writer, message string) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := writeString(writer, message)
+	if err != nil {
+		return err
+	}
+	return client.BanchoIO.WritePacket(stream, BanchoAnnounce, writer.Bytes())
+}
+
+func (client *b20120812) WriteLoginPermissions(stream io.Writer, permissions uint32) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := writeUint32(writer, permissions)
+	if err != nil {
+		return err
+	}
+	return client.BanchoIO.WritePacket(stream, BanchoLoginPermissions, writer.Bytes())
+}
+
+func (client *b20120812) WriteFriendsList(stream io.Writer, userIds []int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := writeIntList16(writer, userIds)
+	if err != nil {
+		return err
+	}
+	return client.BanchoIO.WritePacket(stream, BanchoFriendsList, writer.Bytes())
+}
+
+func (client *b20120812) WriteProtocolNegotiation(stream io.Writer, version int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := writeInt32(writer, version)
+	if err != nil {
+		return err
+	}
+	return client.BanchoIO.WritePacket(stream, BanchoProtocolNegotiation, writer.Bytes())
+}
+
+func (client *b20120812) WriteRestart(stream io.Writer, retryMs int32) error {
+	writer := bytes.NewBuffer([]byte{})
+	err := writeInt32(writer, retryMs)
+	if err != nil {
+		return err
+	}
+	return client.BanchoIO.WritePacket(stream, BanchoRestart, writer.Bytes())
+}
+
+// initB20120812Packets registers the generated packets of b20120812
+func initB20120812Packets(client *b20120812) {
+	client.readers[BanchoAnnounce] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readString(reader)
+	}
+	client.readers[BanchoLoginPermissions] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readUint32(reader)
+	}
+	client.readers[BanchoFriendsList] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readIntList16(reader)
+	}
+	client.readers[BanchoProtocolNegotiation] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+	client.readers[BanchoRestart] = func(c BanchoIO, reader io.Reader) (any, error) {
+		return readInt32(reader)
+	}
+
+	client.supportedPackets = append(client.supportedPackets,
+		BanchoGetAttention,
+		BanchoAnnounce,
+		BanchoLoginPermissions,
+		BanchoFriendsList,
+		BanchoProtocolNegotiation,
+		BanchoRestart,
+	)
+}
@@ -0,0 +1,158 @@
+// Code generated by chiogen. DO NOT EDIT.
+
+package chio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratedB20120812WriteGetAttention(t *testing.T) {
+	client := newB20120812()
+	stream := bytes.NewBuffer([]byte{})
+
+	err := client.WriteGetAttention(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Id != BanchoGetAttention {
+		t.Fatalf("expected packet %d, got %d", BanchoGetAttention, packet.Id)
+	}
+	if !reflect.DeepEqual(packet.Data, nil) {
+		t.Fatalf("expected %#v, got %#v", nil, packet.Data)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("%d bytes left after reading the packet", stream.Len())
+	}
+}
+
+func TestGeneratedB20120812WriteAnnouncement(t *testing.T) {
+	client := newB20120812()
+	stream := bytes.NewBuffer([]byte{})
+	message := string("chio")
+
+	err := client.WriteAnnouncement(stream, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Id != BanchoAnnounce {
+		t.Fatalf("expected packet %d, got %d", BanchoAnnounce, packet.Id)
+	}
+	if !reflect.DeepEqual(packet.Data, message) {
+		t.Fatalf("expected %#v, got %#v", message, packet.Data)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("%d bytes left after reading the packet", stream.Len())
+	}
+}
+
+func TestGeneratedB20120812WriteLoginPermissions(t *testing.T) {
+	client := newB20120812()
+	stream := bytes.NewBuffer([]byte{})
+	permissions := uint32(4000000000)
+
+	err := client.WriteLoginPermissions(stream, permissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Id != BanchoLoginPermissions {
+		t.Fatalf("expected packet %d, got %d", BanchoLoginPermissions, packet.Id)
+	}
+	if !reflect.DeepEqual(packet.Data, permissions) {
+		t.Fatalf("expected %#v, got %#v", permissions, packet.Data)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("%d bytes left after reading the packet", stream.Len())
+	}
+}
+
+func TestGeneratedB20120812WriteFriendsList(t *testing.T) {
+	client := newB20120812()
+	stream := bytes.NewBuffer([]byte{})
+	userIds := []int32([]int32{2, 3, 1000})
+
+	err := client.WriteFriendsList(stream, userIds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Id != BanchoFriendsList {
+		t.Fatalf("expected packet %d, got %d", BanchoFriendsList, packet.Id)
+	}
+	if !reflect.DeepEqual(packet.Data, userIds) {
+		t.Fatalf("expected %#v, got %#v", userIds, packet.Data)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("%d bytes left after reading the packet", stream.Len())
+	}
+}
+
+func TestGeneratedB20120812WriteProtocolNegotiation(t *testing.T) {
+	client := newB20120812()
+	stream := bytes.NewBuffer([]byte{})
+	version := int32(-2000000000)
+
+	err := client.WriteProtocolNegotiation(stream, version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Id != BanchoProtocolNegotiation {
+		t.Fatalf("expected packet %d, got %d", BanchoProtocolNegotiation, packet.Id)
+	}
+	if !reflect.DeepEqual(packet.Data, version) {
+		t.Fatalf("expected %#v, got %#v", version, packet.Data)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("%d bytes left after reading the packet", stream.Len())
+	}
+}
+
+func TestGeneratedB20120812WriteRestart(t *testing.T) {
+	client := newB20120812()
+	stream := bytes.NewBuffer([]byte{})
+	retryMs := int32(-2000000000)
+
+	err := client.WriteRestart(stream, retryMs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := client.ReadPacket(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packet.Id != BanchoRestart {
+		t.Fatalf("expected packet %d, got %d", BanchoRestart, packet.Id)
+	}
+	if !reflect.DeepEqual(packet.Data, retryMs) {
+		t.Fatalf("expected %#v, got %#v", retryMs, packet.Data)
+	}
+	if stream.Len() > 0 {
+		t.Fatalf("%d bytes left after reading the packet", stream.Len())
+	}
+}
@@ -0,0 +1,198 @@
+package chio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// PacketHandler is a function that handles a decoded packet
+type PacketHandler func(packet *BanchoPacket) error
+
+// Middleware wraps a packet handler, and is able to run
+// code before & after it, or to prevent it from running
+type Middleware func(next PacketHandler) PacketHandler
+
+// Router dispatches packets to the handlers that were
+// registered for their packet id
+type Router struct {
+	handlers   map[uint16]PacketHandler
+	fallback   PacketHandler
+	middleware []Middleware
+}
+
+// NewRouter creates a router without any handlers
+func NewRouter() *Router {
+	return &Router{handlers: make(map[uint16]PacketHandler)}
+}
+
+// Handle registers a handler for the given packetId, replacing any previous one
+func (router *Router) Handle(packetId uint16, handler PacketHandler) {
+	router.handlers[packetId] = handler
+}
+
+// Fallback registers a handler for packets that have no handler of their own,
+// as well as for packets that were skipped as an UnknownPacket
+func (router *Router) Fallback(handler PacketHandler) {
+	router.fallback = handler
+}
+
+// Use adds middleware to the router, which will run for every dispatched packet.
+// Middleware that was added first will run first.
+func (router *Router) Use(middleware ...Middleware) {
+	router.middleware = append(router.middleware, middleware...)
+}
+
+// Dispatch runs the handler for the packet, wrapped inside the middleware
+func (router *Router) Dispatch(packet *BanchoPacket) error {
+	handler, ok := router.handlers[packet.Id]
+	if _, unknown := packet.Data.(*UnknownPacket); !ok || unknown {
+		handler = router.fallback
+	}
+	if handler == nil {
+		// Unhandled packets are ignored
+		handler = func(packet *BanchoPacket) error { return nil }
+	}
+
+	for i := len(router.middleware) - 1; i >= 0; i-- {
+		handler = router.middleware[i](handler)
+	}
+
+	return handler(packet)
+}
+
+// Serve reads packets from the stream and dispatches them, until
+// the stream has been closed or a handler returned an error
+func (router *Router) Serve(client BanchoIO, stream io.Reader) error {
+	for {
+		packet, err := client.ReadPacket(stream)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = router.Dispatch(packet)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (router *Router) OnUserStatus(handler func(*UserStatus) error) {
+	router.Handle(OsuSendUserStatus, typedHandler(handler))
+}
+
+func (router *Router) OnMessage(handler func(*Message) error) {
+	router.Handle(OsuSendIrcMessage, typedHandler(handler))
+}
+
+func (router *Router) OnPrivateMessage(handler func(*Message) error) {
+	router.Handle(OsuSendIrcMessagePrivate, typedHandler(handler))
+}
+
+func (router *Router) OnExit(handler func() error) {
+	router.Handle(OsuExit, emptyHandler(handler))
+}
+
+func (router *Router) OnStatusUpdateRequest(handler func() error) {
+	router.Handle(OsuRequestStatusUpdate, emptyHandler(handler))
+}
+
+func (router *Router) OnPong(handler func() error) {
+	router.Handle(OsuPong, emptyHandler(handler))
+}
+
+func (router *Router) OnStartSpectating(handler func(userId int32) error) {
+	router.Handle(OsuStartSpectating, typedHandler(func(userId uint32) error {
+		return handler(int32(userId))
+	}))
+}
+
+func (router *Router) OnStopSpectating(handler func() error) {
+	router.Handle(OsuStopSpectating, emptyHandler(handler))
+}
+
+func (router *Router) OnSpectateFrames(handler func(*ReplayFrameBundle) error) {
+	router.Handle(OsuSpectateFrames, typedHandler(handler))
+}
+
+func (router *Router) OnErrorReport(handler func(report string) error) {
+	router.Handle(OsuErrorReport, typedHandler(handler))
+}
+
+func (router *Router) OnCantSpectate(handler func() error) {
+	router.Handle(OsuCantSpectate, emptyHandler(handler))
+}
+
+func (router *Router) OnChannelJoin(handler func(channel string) error) {
+	router.Handle(OsuChannelJoin, typedHandler(handler))
+}
+
+func (router *Router) OnChannelLeave(handler func(channel string) error) {
+	router.Handle(OsuChannelLeave, typedHandler(handler))
+}
+
+func (router *Router) OnLobbyJoin(handler func() error) {
+	router.Handle(OsuLobbyJoin, emptyHandler(handler))
+}
+
+func (router *Router) OnLobbyPart(handler func() error) {
+	router.Handle(OsuLobbyPart, emptyHandler(handler))
+}
+
+func (router *Router) OnMatchCreate(handler func(*Match) error) {
+	router.Handle(OsuMatchCreate, typedHandler(handler))
+}
+
+func (router *Router) OnMatchJoin(handler func(*MatchJoin) error) {
+	router.Handle(OsuMatchJoin, typedHandler(handler))
+}
+
+func (router *Router) OnMatchPart(handler func() error) {
+	router.Handle(OsuMatchPart, emptyHandler(handler))
+}
+
+func (router *Router) OnMatchChangeSlot(handler func(slotId int32) error) {
+	router.Handle(OsuMatchChangeSlot, typedHandler(handler))
+}
+
+func (router *Router) OnMatchReady(handler func() error) {
+	router.Handle(OsuMatchReady, emptyHandler(handler))
+}
+
+func (router *Router) OnMatchLock(handler func(slotId int32) error) {
+	router.Handle(OsuMatchLock, typedHandler(handler))
+}
+
+func (router *Router) OnMatchChangeSettings(handler func(*Match) error) {
+	router.Handle(OsuMatchChangeSettings, typedHandler(handler))
+}
+
+func (router *Router) OnMatchStart(handler func() error) {
+	router.Handle(OsuMatchStart, emptyHandler(handler))
+}
+
+func (router *Router) OnMatchScoreUpdate(handler func(*ScoreFrame) error) {
+	router.Handle(OsuMatchScoreUpdate, typedHandler(handler))
+}
+
+// typedHandler converts the packet data to the type
+// that is expected by the handler
+func typedHandler[T any](handler func(T) error) PacketHandler {
+	return func(packet *BanchoPacket) error {
+		data, ok := packet.Data.(T)
+		if !ok {
+			return fmt.Errorf("unexpected data '%T' for packet '%d'", packet.Data, packet.Id)
+		}
+		return handler(data)
+	}
+}
+
+// emptyHandler is used for packets that don't contain any data
+func emptyHandler(handler func() error) PacketHandler {
+	return func(packet *BanchoPacket) error {
+		return handler()
+	}
+}
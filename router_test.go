@@ -0,0 +1,211 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRouterDispatch(t *testing.T) {
+	router := NewRouter()
+	message := &Message{Sender: "peppy", Content: "hello", Target: "#osu"}
+
+	var received *Message
+	router.OnMessage(func(m *Message) error {
+		received = m
+		return nil
+	})
+
+	var spectating int32
+	router.OnStartSpectating(func(userId int32) error {
+		spectating = userId
+		return nil
+	})
+
+	pong := false
+	router.OnPong(func() error {
+		pong = true
+		return nil
+	})
+
+	if err := router.Dispatch(&BanchoPacket{Id: OsuSendIrcMessage, Data: message}); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Dispatch(&BanchoPacket{Id: OsuStartSpectating, Data: uint32(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := router.Dispatch(&BanchoPacket{Id: OsuPong}); err != nil {
+		t.Fatal(err)
+	}
+
+	if received != message {
+		t.Errorf("expected message %#v, got %#v", message, received)
+	}
+	if spectating != 2 {
+		t.Errorf("expected to spectate user 2, got %d", spectating)
+	}
+	if !pong {
+		t.Error("expected the pong handler to run")
+	}
+
+	// Packets without a handler are ignored
+	if err := router.Dispatch(&BanchoPacket{Id: OsuExit}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouterMiddlewareOrder(t *testing.T) {
+	router := NewRouter()
+	calls := []string{}
+
+	trace := func(name string) Middleware {
+		return func(next PacketHandler) PacketHandler {
+			return func(packet *BanchoPacket) error {
+				calls = append(calls, name+" before")
+				err := next(packet)
+				calls = append(calls, name+" after")
+				return err
+			}
+		}
+	}
+
+	router.Use(trace("first"), trace("second"))
+	router.Use(trace("third"))
+	router.OnPong(func() error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	if err := router.Dispatch(&BanchoPacket{Id: OsuPong}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"first before", "second before", "third before",
+		"handler",
+		"third after", "second after", "first after",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected calls %v, got %v", expected, calls)
+	}
+
+	// Middleware is able to prevent the handler from running
+	calls = calls[:0]
+	blocked := errors.New("blocked")
+	router.Use(func(next PacketHandler) PacketHandler {
+		return func(packet *BanchoPacket) error { return blocked }
+	})
+
+	if err := router.Dispatch(&BanchoPacket{Id: OsuPong}); !errors.Is(err, blocked) {
+		t.Fatalf("expected the middleware error, got %v", err)
+	}
+	for _, call := range calls {
+		if call == "handler" {
+			t.Fatal("expected the handler not to run")
+		}
+	}
+}
+
+func TestRouterFallback(t *testing.T) {
+	router := NewRouter()
+	fallback := []uint16{}
+
+	router.OnPong(func() error { return nil })
+	router.OnErrorReport(func(report string) error {
+		t.Fatal("expected unknown packets to skip their handler")
+		return nil
+	})
+	router.Fallback(func(packet *BanchoPacket) error {
+		fallback = append(fallback, packet.Id)
+		return nil
+	})
+
+	packets := []*BanchoPacket{
+		{Id: OsuPong},
+		{Id: OsuExit},
+		{Id: OsuErrorReport, Data: &UnknownPacket{Payload: []byte{0x0b}}},
+	}
+
+	for _, packet := range packets {
+		if err := router.Dispatch(packet); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []uint16{OsuExit, OsuErrorReport}
+	if !reflect.DeepEqual(fallback, expected) {
+		t.Fatalf("expected fallback for %v, got %v", expected, fallback)
+	}
+}
+
+func TestRouterUnexpectedData(t *testing.T) {
+	router := NewRouter()
+	router.OnMessage(func(m *Message) error {
+		t.Fatal("expected the handler not to run")
+		return nil
+	})
+
+	err := router.Dispatch(&BanchoPacket{Id: OsuSendIrcMessage, Data: "hello"})
+	if err == nil {
+		t.Fatal("expected an error for unexpected packet data")
+	}
+}
+
+func TestRouterServe(t *testing.T) {
+	client := newTestClient(t, 282)
+	client.OverrideSkipUnknownPackets(true)
+	message := Message{Sender: "peppy", Content: "hello", Target: "#osu"}
+
+	stream := bytes.NewBuffer([]byte{})
+	client.WriteOsuMessage(stream, message)
+	client.WriteOsuPong(stream)
+	// Channels are not supported by b282, and will be passed to the fallback
+	client.WritePacket(stream, OsuChannelJoin, []byte{0x0b, 0x04, '#', 'o', 's', 'u'})
+
+	received := []uint16{}
+	router := NewRouter()
+	router.OnMessage(func(m *Message) error {
+		if m.Content != message.Content {
+			t.Errorf("expected message %q, got %q", message.Content, m.Content)
+		}
+		received = append(received, OsuSendIrcMessage)
+		return nil
+	})
+	router.OnPong(func() error {
+		received = append(received, OsuPong)
+		return nil
+	})
+	router.Fallback(func(packet *BanchoPacket) error {
+		if _, ok := packet.Data.(*UnknownPacket); !ok {
+			t.Errorf("expected unknown packet data, got %T", packet.Data)
+		}
+		received = append(received, packet.Id)
+		return nil
+	})
+
+	// The stream ends after the last packet, which is not an error
+	if err := router.Serve(client, stream); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []uint16{OsuSendIrcMessage, OsuPong, OsuChannelJoin}
+	if !reflect.DeepEqual(received, expected) {
+		t.Fatalf("expected packets %v, got %v", expected, received)
+	}
+}
+
+func TestRouterServeHandlerError(t *testing.T) {
+	client := newTestClient(t, 282)
+	stream := bytes.NewBuffer([]byte{})
+	client.WriteOsuPong(stream)
+	client.WriteOsuPong(stream)
+
+	failed := errors.New("failed")
+	router := NewRouter()
+	router.OnPong(func() error { return failed })
+
+	if err := router.Serve(client, stream); !errors.Is(err, failed) {
+		t.Fatalf("expected the handler error, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package chio
+
+import (
+	"bytes"
+	"io"
+)
+
+// PacketStream reads packets from a stream, and reuses its buffers
+// between packets to avoid allocations on busy connections.
+// Apart from the decoded packet data, reading a packet does not allocate,
+// as long as the stream implements io.ByteReader, e.g. a bufio.Reader.
+// A PacketStream is not safe for concurrent use.
+type PacketStream struct {
+	client BanchoIO
+	stream io.Reader
+	packet BanchoPacket
+
+	data         []byte
+	decompressed bytes.Buffer
+	payload      bytes.Reader
+}
+
+// NewPacketStream creates a PacketStream that reads
+// packets from the stream using the given client
+func NewPacketStream(client BanchoIO, stream io.Reader) *PacketStream {
+	return &PacketStream{client: client, stream: stream}
+}
+
+// Next reads the next packet from the stream.
+// The returned packet will be overwritten by the next call to Next,
+// however the decoded packet data itself is never reused.
+func (ps *PacketStream) Next() (*BanchoPacket, error) {
+	header, err := ps.client.ReadPacketHeader(ps.stream)
+	if err != nil {
+		return nil, err
+	}
+
+	implemented := ps.client.ImplementsPacket(header.Id)
+	skip := ps.client.SkipUnknownPackets()
+
+	if !implemented && !skip {
+		return nil, &ErrUnsupportedPacket{Id: header.Id, Version: ps.client.Version()}
+	}
+
+	err = validatePacketLength(header.Length, ps.client.MaxPacketSize())
+	if err != nil {
+		return nil, err
+	}
+
+	if cap(ps.data) < int(header.Length) {
+		ps.data = make([]byte, header.Length)
+	}
+	ps.data = ps.data[:header.Length]
+
+	_, err = io.ReadFull(ps.stream, ps.data)
+	if err != nil {
+		return nil, shortPacketError(err)
+	}
+
+	data := ps.data
+
+	if header.Compressed {
+		ps.decompressed.Reset()
+		limit := decompressionLimit(ps.client, len(ps.data))
+
+		err = decompressInto(&ps.decompressed, ps.data, limit)
+		if err != nil {
+			return nil, err
+		}
+		data = ps.decompressed.Bytes()
+	}
+
+	ps.packet.Id = header.Id
+	ps.packet.Data = nil
+	ps.payload.Reset(data)
+
+	if !implemented {
+		unsupported := &ErrUnsupportedPacket{Id: header.Id, Version: ps.client.Version()}
+		ps.packet.Data = &UnknownPacket{Payload: bytes.Clone(data), Err: unsupported}
+		return &ps.packet, nil
+	}
+
+	reader, ok := ps.client.GetReaders()[header.Id]
+
+	if ok {
+		ps.packet.Data, err = decodePacket(ps.client, reader, header.Id, &ps.payload)
+		if err != nil && skip {
+			ps.packet.Data = &UnknownPacket{Payload: bytes.Clone(data), Err: err}
+			return &ps.packet, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ps.packet, nil
+}
@@ -0,0 +1,149 @@
+package chio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func newTestFrameBundle() ReplayFrameBundle {
+	return ReplayFrameBundle{
+		Action: 0,
+		Frames: []*ReplayFrame{
+			{ButtonState: ButtonStateLeft1, MouseX: 256, MouseY: 192, Time: 1000},
+			{ButtonState: ButtonStateLeft1, MouseX: 260, MouseY: 190, Time: 1016},
+			{ButtonState: 0, MouseX: 264, MouseY: 188, Time: 1032},
+		},
+	}
+}
+
+func TestPacketStream(t *testing.T) {
+	client := newTestClient(t, 282)
+	bundle := newTestFrameBundle()
+
+	data := bytes.NewBuffer([]byte{})
+	client.WriteSpectateFrames(data, bundle)
+	client.WritePing(data)
+	client.WriteSpectateFrames(data, bundle)
+
+	stream := NewPacketStream(client, data)
+	expected := []uint16{BanchoSpectateFrames, BanchoPing, BanchoSpectateFrames}
+
+	for _, id := range expected {
+		packet, err := stream.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packet.Id != id {
+			t.Fatalf("expected packet %d, got %d", id, packet.Id)
+		}
+		if id == BanchoSpectateFrames {
+			expectPacket(t, packet, id, &bundle)
+		}
+	}
+
+	if _, err := stream.Next(); err != io.EOF {
+		t.Fatalf("expected io.EOF after the last packet, got %v", err)
+	}
+}
+
+func TestPacketStreamSkipsUnknownPackets(t *testing.T) {
+	client := newTestClient(t, 282)
+	client.OverrideSkipUnknownPackets(true)
+
+	data := bytes.NewBuffer([]byte{})
+	newTestClient(t, 294).WriteChannelJoinSuccess(data, "#osu")
+	client.WritePing(data)
+
+	stream := NewPacketStream(client, data)
+
+	packet, err := stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := packet.Data.(*UnknownPacket); !ok {
+		t.Fatalf("expected an unknown packet, got %#v", packet.Data)
+	}
+
+	packet, err = stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoPing, nil)
+}
+
+func TestPacketStreamAllocations(t *testing.T) {
+	for _, version := range []int{282, 20121223} {
+		client := newTestClient(t, version)
+		data := encodeTestFrames(t, version)
+		source := bytes.NewReader(data)
+		stream := NewPacketStream(client, source)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			source.Reset(data)
+			if _, err := stream.Next(); err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		// The bundle, its list of frames & the three frames themselves
+		if allocs > 5 {
+			t.Errorf("b%d: expected only the packet data to be allocated, got %v allocations", version, allocs)
+		}
+	}
+}
+
+func encodeTestFrames(tb testing.TB, version int) []byte {
+	client := newTestClient(tb, version)
+	data := bytes.NewBuffer([]byte{})
+	bundle := newTestFrameBundle()
+
+	if err := client.WriteSpectateFrames(data, bundle); err != nil {
+		tb.Fatal(err)
+	}
+	return data.Bytes()
+}
+
+func BenchmarkPacketStreamNext(b *testing.B) {
+	for _, version := range []int{282, 20121223} {
+		b.Run(fmt.Sprintf("b%d", version), func(b *testing.B) {
+			client := newTestClient(b, version)
+			data := encodeTestFrames(b, version)
+			source := bytes.NewReader(data)
+			stream := NewPacketStream(client, source)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				source.Reset(data)
+				if _, err := stream.Next(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadPacket(b *testing.B) {
+	for _, version := range []int{282, 20121223} {
+		b.Run(fmt.Sprintf("b%d", version), func(b *testing.B) {
+			client := newTestClient(b, version)
+			data := encodeTestFrames(b, version)
+			source := bytes.NewReader(data)
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				source.Reset(data)
+				if _, err := client.ReadPacket(source); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
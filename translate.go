@@ -0,0 +1,278 @@
+package chio
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// Translator translates packets between clients of different versions
+type Translator struct {
+	// OnLossyFrame is called for every replay frame, whose button state
+	// can't be fully represented by the target client
+	OnLossyFrame func(target BanchoIO, frame *ReplayFrame, conversion ButtonStateConversion)
+
+	// OnDroppedPacket is called for every packet that can't be translated,
+	// because it has no writer, was skipped by the input client, or
+	// doesn't contain enough data for the target client
+	OnDroppedPacket func(target BanchoIO, packet *BanchoPacket)
+}
+
+// TranslatePacket writes an already decoded packet to the stream,
+// using the writer of the target client that matches the packet id.
+// Packets that have no writer, or were skipped by the input client will
+// be dropped, the same way as unsupported packets are dropped by the writers.
+// Use a Translator with OnDroppedPacket to find out about dropped packets.
+func TranslatePacket(target BanchoIO, stream io.Writer, packet *BanchoPacket) error {
+	return (&Translator{}).TranslatePacket(target, stream, packet)
+}
+
+// Translate reads packets from the source stream using the input client,
+// and re-emits them to the target stream through the output client.
+// It returns once the source stream has been closed, or an error occurred.
+func Translate(input BanchoIO, output BanchoIO, source io.Reader, target io.Writer) error {
+	return (&Translator{}).Translate(input, output, source, target)
+}
+
+// Proxy relays packets in both directions between an osu! client and a
+// bancho server, which may be using different protocol versions.
+// It returns the error of the direction that stopped first. If both streams
+// implement io.Closer, they will be closed to stop the other direction, and
+// Proxy waits for it to return. Otherwise the caller has to close them.
+func Proxy(client io.ReadWriter, clientIO BanchoIO, server io.ReadWriter, serverIO BanchoIO) error {
+	return (&Translator{}).Proxy(client, clientIO, server, serverIO)
+}
+
+// TranslatePacket works like the TranslatePacket function, and reports lossy replay frames & dropped packets
+func (translator *Translator) TranslatePacket(target BanchoIO, stream io.Writer, packet *BanchoPacket) (err error) {
+	defer HandlePanic(&err)
+
+	if _, ok := packet.Data.(*UnknownPacket); ok {
+		// Skipped packets can't be translated
+		translator.reportDroppedPacket(target, packet)
+		return nil
+	}
+
+	if bundle, ok := packet.Data.(*ReplayFrameBundle); ok {
+		translator.reportLossyFrames(target, bundle)
+	}
+
+	switch packet.Id {
+	case OsuSendUserStatus:
+		return target.WriteOsuUserStatus(stream, *packet.Data.(*UserStatus))
+	case OsuSendIrcMessage:
+		return target.WriteOsuMessage(stream, *packet.Data.(*Message))
+	case OsuExit:
+		return target.WriteOsuExit(stream)
+	case OsuRequestStatusUpdate:
+		return target.WriteOsuStatusUpdateRequest(stream)
+	case OsuPong:
+		return target.WriteOsuPong(stream)
+	case OsuStartSpectating:
+		return target.WriteOsuStartSpectating(stream, int32(packet.Data.(uint32)))
+	case OsuStopSpectating:
+		return target.WriteOsuStopSpectating(stream)
+	case OsuSpectateFrames:
+		return target.WriteOsuSpectateFrames(stream, *packet.Data.(*ReplayFrameBundle))
+	case OsuErrorReport:
+		return target.WriteOsuErrorReport(stream, packet.Data.(string))
+	case OsuCantSpectate:
+		return target.WriteOsuCantSpectate(stream)
+	case OsuSendIrcMessagePrivate:
+		return target.WriteOsuPrivateMessage(stream, *packet.Data.(*Message))
+	case OsuChannelJoin:
+		return target.WriteOsuChannelJoin(stream, packet.Data.(string))
+	case OsuChannelLeave:
+		return target.WriteOsuChannelLeave(stream, packet.Data.(string))
+	case OsuLobbyPart:
+		return target.WriteOsuLobbyPart(stream)
+	case OsuLobbyJoin:
+		return target.WriteOsuLobbyJoin(stream)
+	case OsuMatchCreate:
+		return target.WriteOsuMatchCreate(stream, *packet.Data.(*Match))
+	case OsuMatchJoin:
+		return target.WriteOsuMatchJoin(stream, *packet.Data.(*MatchJoin))
+	case OsuMatchPart:
+		return target.WriteOsuMatchPart(stream)
+	case OsuMatchChangeSlot:
+		return target.WriteOsuMatchChangeSlot(stream, packet.Data.(int32))
+	case OsuMatchReady:
+		return target.WriteOsuMatchReady(stream)
+	case OsuMatchLock:
+		return target.WriteOsuMatchLock(stream, packet.Data.(int32))
+	case OsuMatchChangeSettings:
+		return target.WriteOsuMatchChangeSettings(stream, *packet.Data.(*Match))
+	case OsuMatchStart:
+		return target.WriteOsuMatchStart(stream)
+	case OsuMatchScoreUpdate:
+		return target.WriteOsuMatchScoreUpdate(stream, *packet.Data.(*ScoreFrame))
+	case OsuMatchComplete:
+		return target.WriteOsuMatchComplete(stream)
+
+	case BanchoLoginReply:
+		return target.WriteLoginReply(stream, packet.Data.(int32))
+	case BanchoCommandError:
+		return target.WriteCommandError(stream)
+	case BanchoSendMessage:
+		return target.WriteMessage(stream, *packet.Data.(*Message))
+	case BanchoPing:
+		return target.WritePing(stream)
+	case BanchoHandleIrcChangeUsername:
+		oldName, newName, _ := strings.Cut(packet.Data.(string), ">>>>")
+		return target.WriteIrcChangeUsername(stream, oldName, newName)
+	case BanchoHandleIrcJoin, BanchoHandleOsuUpdate:
+		return target.WriteUserStats(stream, *packet.Data.(*UserInfo))
+	case BanchoHandleIrcQuit, BanchoHandleOsuQuit:
+		return target.WriteUserQuit(stream, *packet.Data.(*UserQuit))
+	case BanchoSpectatorJoined:
+		return target.WriteSpectatorJoined(stream, packet.Data.(int32))
+	case BanchoSpectatorLeft:
+		return target.WriteSpectatorLeft(stream, packet.Data.(int32))
+	case BanchoSpectateFrames:
+		return target.WriteSpectateFrames(stream, *packet.Data.(*ReplayFrameBundle))
+	case BanchoVersionUpdate:
+		return target.WriteVersionUpdate(stream)
+	case BanchoSpectatorCantSpectate:
+		return target.WriteSpectatorCantSpectate(stream, packet.Data.(int32))
+	case BanchoChannelJoinSuccess:
+		return target.WriteChannelJoinSuccess(stream, packet.Data.(string))
+	case BanchoChannelRevoked:
+		return target.WriteChannelRevoked(stream, packet.Data.(string))
+	case BanchoChannelAvailable:
+		return target.WriteChannelAvailable(stream, *packet.Data.(*Channel))
+	case BanchoChannelAvailableAutojoin:
+		return target.WriteChannelAvailableAutojoin(stream, *packet.Data.(*Channel))
+	case BanchoMatchUpdate:
+		return target.WriteMatchUpdate(stream, *packet.Data.(*Match))
+	case BanchoMatchNew:
+		return target.WriteMatchNew(stream, *packet.Data.(*Match))
+	case BanchoMatchDisband:
+		return target.WriteMatchDisband(stream, packet.Data.(int32))
+	case BanchoLobbyJoin:
+		return target.WriteLobbyJoin(stream, packet.Data.(int32))
+	case BanchoLobbyPart:
+		return target.WriteLobbyPart(stream, packet.Data.(int32))
+	case BanchoMatchJoinSuccess:
+		return target.WriteMatchJoinSuccess(stream, *packet.Data.(*Match))
+	case BanchoMatchJoinFail:
+		return target.WriteMatchJoinFail(stream)
+	case BanchoMatchStart:
+		return target.WriteMatchStart(stream, *packet.Data.(*Match))
+	case BanchoMatchScoreUpdate:
+		return target.WriteMatchScoreUpdate(stream, *packet.Data.(*ScoreFrame))
+	case BanchoMatchTransferHost:
+		return target.WriteMatchTransferHost(stream)
+	case BanchoMatchAllPlayersLoaded:
+		return target.WriteMatchAllPlayersLoaded(stream)
+	case BanchoMatchComplete:
+		return target.WriteMatchComplete(stream)
+	case BanchoMatchSkip:
+		return target.WriteMatchSkip(stream)
+	case BanchoUnauthorized:
+		return target.WriteUnauthorized(stream)
+	case BanchoUserPresence:
+		return target.WriteUserPresence(stream, *packet.Data.(*UserInfo))
+	case BanchoUserPresenceSingle:
+		if !target.ImplementsPacket(BanchoUserPresenceSingle) {
+			// Only the user id is known, which is not enough for older clients
+			translator.reportDroppedPacket(target, packet)
+			return nil
+		}
+		return target.WriteUserPresenceSingle(stream, UserInfo{Id: packet.Data.(int32)})
+	case BanchoUserPresenceBundle:
+		if !target.ImplementsPacket(BanchoUserPresenceBundle) {
+			translator.reportDroppedPacket(target, packet)
+			return nil
+		}
+		userIds := packet.Data.([]int32)
+		infos := make([]UserInfo, len(userIds))
+		for i, userId := range userIds {
+			infos[i] = UserInfo{Id: userId}
+		}
+		return target.WriteUserPresenceBundle(stream, infos)
+	case BanchoGetAttention:
+		return target.WriteGetAttention(stream)
+	case BanchoAnnounce:
+		return target.WriteAnnouncement(stream, packet.Data.(string))
+	case BanchoLoginPermissions:
+		return target.WriteLoginPermissions(stream, packet.Data.(uint32))
+	case BanchoFriendsList:
+		return target.WriteFriendsList(stream, packet.Data.([]int32))
+	case BanchoProtocolNegotiation:
+		return target.WriteProtocolNegotiation(stream, packet.Data.(int32))
+	case BanchoRestart:
+		return target.WriteRestart(stream, packet.Data.(int32))
+	}
+
+	translator.reportDroppedPacket(target, packet)
+	return nil
+}
+
+func (translator *Translator) reportDroppedPacket(target BanchoIO, packet *BanchoPacket) {
+	if translator.OnDroppedPacket != nil {
+		translator.OnDroppedPacket(target, packet)
+	}
+}
+
+// reportLossyFrames calls OnLossyFrame for the frames of the bundle,
+// that will lose information when they are written by the target client
+func (translator *Translator) reportLossyFrames(target BanchoIO, bundle *ReplayFrameBundle) {
+	if translator.OnLossyFrame == nil {
+		return
+	}
+
+	for _, frame := range bundle.Frames {
+		conversion := target.ConvertButtonState(frame.ButtonState)
+		if conversion.IsLossy() {
+			translator.OnLossyFrame(target, frame, conversion)
+		}
+	}
+}
+
+// Translate works like the Translate function, and reports lossy replay frames & dropped packets
+func (translator *Translator) Translate(input BanchoIO, output BanchoIO, source io.Reader, target io.Writer) error {
+	for {
+		packet, err := input.ReadPacket(source)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = translator.TranslatePacket(output, target, packet)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Proxy works like the Proxy function, and reports lossy replay frames & dropped packets
+func (translator *Translator) Proxy(client io.ReadWriter, clientIO BanchoIO, server io.ReadWriter, serverIO BanchoIO) error {
+	errs := make(chan error, 2)
+
+	go func() {
+		errs <- translator.Translate(clientIO, serverIO, client, server)
+	}()
+	go func() {
+		errs <- translator.Translate(serverIO, clientIO, server, client)
+	}()
+
+	err := <-errs
+
+	clientCloser, ok := client.(io.Closer)
+	if !ok {
+		return err
+	}
+	serverCloser, ok := server.(io.Closer)
+	if !ok {
+		return err
+	}
+
+	clientCloser.Close()
+	serverCloser.Close()
+
+	// The other direction will fail because of the closed streams
+	<-errs
+	return err
+}
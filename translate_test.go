@@ -0,0 +1,216 @@
+package chio
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTranslate(t *testing.T) {
+	input := newTestClient(t, 282)
+	output := newTestClient(t, 294)
+
+	message := Message{Sender: "peppy", Content: "hello", Target: "#osu"}
+	bundle := ReplayFrameBundle{
+		Action: 2,
+		Frames: []*ReplayFrame{{ButtonState: ButtonStateLeft1, MouseX: 10, MouseY: 20, Time: 30}},
+	}
+
+	source := bytes.NewBuffer([]byte{})
+	input.WriteMessage(source, message)
+	input.WritePing(source)
+	input.WriteSpectateFrames(source, bundle)
+
+	target := bytes.NewBuffer([]byte{})
+	if err := Translate(input, output, source, target); err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := output.ReadPacket(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoSendMessage, &message)
+
+	packet, err = output.ReadPacket(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoPing, nil)
+
+	packet, err = output.ReadPacket(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoSpectateFrames, &bundle)
+
+	if target.Len() > 0 {
+		t.Fatalf("%d bytes left after reading all packets", target.Len())
+	}
+}
+
+func TestTranslateDropsUnsupportedPackets(t *testing.T) {
+	input := newTestClient(t, 294)
+	output := newTestClient(t, 282)
+
+	source := bytes.NewBuffer([]byte{})
+	input.WriteChannelJoinSuccess(source, "#lobby")
+
+	target := bytes.NewBuffer([]byte{})
+	if err := Translate(input, output, source, target); err != nil {
+		t.Fatal(err)
+	}
+	if target.Len() > 0 {
+		t.Fatalf("expected packet to be dropped, got %d bytes", target.Len())
+	}
+}
+
+func TestTranslatorReportsLossyFrames(t *testing.T) {
+	input := newTestClient(t, 20121223)
+	output := newTestClient(t, 282)
+
+	bundle := ReplayFrameBundle{
+		Frames: []*ReplayFrame{
+			{ButtonState: ButtonStateLeft1, MouseX: 10, MouseY: 20, Time: 30},
+			{ButtonState: ButtonStateLeft2 | ButtonStateSmoke, MouseX: 15, MouseY: 25, Time: 46},
+		},
+	}
+
+	source := bytes.NewBuffer([]byte{})
+	input.WriteSpectateFrames(source, bundle)
+
+	var reports []ButtonStateConversion
+	translator := &Translator{
+		OnLossyFrame: func(target BanchoIO, frame *ReplayFrame, conversion ButtonStateConversion) {
+			if target != output {
+				t.Errorf("expected the output client to be reported")
+			}
+			if frame.Time != 46 {
+				t.Errorf("expected the second frame to be reported, got frame at %d", frame.Time)
+			}
+			reports = append(reports, conversion)
+		},
+	}
+
+	target := bytes.NewBuffer([]byte{})
+	if err := translator.Translate(input, output, source, target); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := ButtonStateConversion{ButtonState: ButtonStateLeft1, Lossy: ButtonStateLeft2 | ButtonStateSmoke}
+	if len(reports) != 1 || reports[0] != expected {
+		t.Fatalf("expected %+v to be reported, got %+v", expected, reports)
+	}
+}
+
+func TestTranslateEmptyPackets(t *testing.T) {
+	input := newTestClient(t, 323)
+	output := newTestClient(t, 20121223)
+
+	source := bytes.NewBuffer([]byte{})
+	input.WriteCommandError(source)
+	input.WriteOsuMatchComplete(source)
+
+	target := bytes.NewBuffer([]byte{})
+	if err := Translate(input, output, source, target); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []uint16{BanchoCommandError, OsuMatchComplete} {
+		packet, err := output.ReadPacket(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectPacket(t, packet, id, nil)
+	}
+
+	if target.Len() > 0 {
+		t.Fatalf("%d bytes left after reading all packets", target.Len())
+	}
+}
+
+func TestTranslatorReportsDroppedPackets(t *testing.T) {
+	input := newTestClient(t, 20121223)
+	input.OverrideSkipUnknownPackets(true)
+	output := newTestClient(t, 282)
+
+	source := bytes.NewBuffer([]byte{})
+	input.WriteUserPresenceSingle(source, UserInfo{Id: 2})
+	input.WritePacket(source, OsuBeatmapInfoRequest, []byte{0, 0, 0, 0})
+	input.WritePing(source)
+
+	var dropped []uint16
+	translator := &Translator{
+		OnDroppedPacket: func(target BanchoIO, packet *BanchoPacket) {
+			if target != output {
+				t.Errorf("expected the output client to be reported")
+			}
+			dropped = append(dropped, packet.Id)
+		},
+	}
+
+	target := bytes.NewBuffer([]byte{})
+	if err := translator.Translate(input, output, source, target); err != nil {
+		t.Fatal(err)
+	}
+
+	// Packets without a writer are reported as well
+	packet := &BanchoPacket{Id: BanchoFellowSpectatorJoined, Data: int32(2)}
+	if err := translator.TranslatePacket(output, target, packet); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []uint16{BanchoUserPresenceSingle, OsuBeatmapInfoRequest, BanchoFellowSpectatorJoined}
+	if !reflect.DeepEqual(dropped, expected) {
+		t.Fatalf("expected dropped packets %v, got %v", expected, dropped)
+	}
+
+	packet, err := output.ReadPacket(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, BanchoPing, nil)
+}
+
+func TestProxy(t *testing.T) {
+	osuClient, proxyClient := net.Pipe()
+	proxyServer, banchoServer := net.Pipe()
+	clientIO := newTestClient(t, 282)
+	serverIO := newTestClient(t, 20121223)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Proxy(proxyClient, clientIO, proxyServer, serverIO)
+	}()
+
+	if err := clientIO.WriteOsuPong(osuClient); err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := serverIO.ReadPacket(banchoServer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, packet, OsuPong, nil)
+
+	// Disconnecting the client should stop both directions
+	osuClient.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected proxy to stop without an error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("proxy did not return after the client disconnected")
+	}
+
+	_, err = banchoServer.Read(make([]byte, 1))
+	if !errors.Is(err, io.EOF) {
+		t.Fatalf("expected server connection to be closed, got %v", err)
+	}
+}
@@ -46,24 +46,38 @@ func (presence *UserPresence) Location() string {
 }
 
 type UserStats struct {
-	Rank      int32
-	Rscore    uint64
-	Tscore    uint64
-	Accuracy  float64
-	Playcount int32
-	PP        uint16
+	Rank      int32   `bancho:"int32,order=5"`
+	Rscore    uint64  `bancho:"uint64,order=1"`
+	Tscore    uint64  `bancho:"uint64,order=4"`
+	Accuracy  float64 `bancho:"float64,order=2,until=20120812;float32,since=20120812"`
+	Playcount int32   `bancho:"int32,order=3"`
+	PP        uint16  `bancho:"uint16,order=6,since=20121223"`
 }
 
 type UserStatus struct {
-	Action          uint8
-	Text            string
-	Mods            uint32
-	Mode            uint8
-	BeatmapChecksum string
-	BeatmapId       int32
+	Action          uint8  `bancho:"uint8,order=1,until=20121223,get=LegacyAction;uint8,since=20121223"`
+	Text            string `bancho:"string,order=2,until=20121223,if=IsKnown;string,since=20121223"`
+	Mods            uint32 `bancho:"uint16,order=4,until=20121223,if=IsKnown;uint32,since=20121223"`
+	Mode            uint8  `bancho:"uint8,order=5,since=20121223"`
+	BeatmapChecksum string `bancho:"string,order=3,until=20121223,if=IsKnown;string,since=20121223"`
+	BeatmapId       int32  `bancho:"int32,order=6,since=20121223"`
 	UpdateStats     bool
 }
 
+// LegacyAction returns the action as it is sent before b20121223,
+// where a stats update is requested through the action itself
+func (status *UserStatus) LegacyAction() uint8 {
+	if status.UpdateStats {
+		return StatusStatsUpdate
+	}
+	return status.Action
+}
+
+// IsKnown checks if the status contains any details about the action
+func (status *UserStatus) IsKnown() bool {
+	return status.LegacyAction() != StatusUnknown
+}
+
 type UserQuit struct {
 	Info      *UserInfo
 	QuitState uint8
@@ -77,10 +91,10 @@ type Message struct {
 }
 
 type Channel struct {
-	Name      string
-	Topic     string
+	Name      string `bancho:"string,since=294"`
+	Topic     string `bancho:"string,since=294"`
 	Owner     string
-	UserCount int16
+	UserCount int16 `bancho:"int16,since=294"`
 }
 
 type BeatmapInfo struct {
@@ -112,10 +126,10 @@ type BeatmapInfoRequest struct {
 }
 
 type ReplayFrame struct {
-	ButtonState uint8
-	MouseX      float32
-	MouseY      float32
-	Time        int32
+	ButtonState uint8   `bancho:"legacybuttons,until=20120812;buttonstate,since=20120812"`
+	MouseX      float32 `bancho:"float32"`
+	MouseY      float32 `bancho:"float32"`
+	Time        int32   `bancho:"int32"`
 }
 
 type ReplayFrameBundle struct {
@@ -126,27 +140,38 @@ type ReplayFrameBundle struct {
 }
 
 type ScoreFrame struct {
-	Time         int32
-	Id           uint8
-	Total300     uint16
-	Total100     uint16
-	Total50      uint16
-	TotalGeki    uint16
-	TotalKatu    uint16
-	TotalMiss    uint16
-	TotalScore   uint32
-	MaxCombo     uint16
-	CurrentCombo uint16
-	Perfect      bool
-	Hp           uint8
-	TagByte      uint8
-}
-
-// ScoreFrame checksum calculation used in version b323
+	Time         int32  `bancho:"int32,since=323"`
+	Id           uint8  `bancho:"uint8,since=323"`
+	Total300     uint16 `bancho:"uint16,since=323"`
+	Total100     uint16 `bancho:"uint16,since=323"`
+	Total50      uint16 `bancho:"uint16,since=323"`
+	TotalGeki    uint16 `bancho:"uint16,since=323"`
+	TotalKatu    uint16 `bancho:"uint16,since=323"`
+	TotalMiss    uint16 `bancho:"uint16,since=323"`
+	TotalScore   uint32 `bancho:"uint32,since=323"`
+	MaxCombo     uint16 `bancho:"uint16,since=323"`
+	CurrentCombo uint16 `bancho:"uint16,since=323"`
+	Perfect      bool   `bancho:"bool,since=323"`
+	Hp           uint8  `bancho:"uint8,since=323"`
+	TagByte      uint8  `bancho:"uint8,since=20120812"`
+
+	// ScoreV2 frames contain the combo & bonus portion of the score
+	ScoreV2      bool    `bancho:"bool,since=20160403"`
+	ComboPortion float64 `bancho:"float64,since=20160403,if=ScoreV2"`
+	BonusPortion float64 `bancho:"float64,since=20160403,if=ScoreV2"`
+
+	// Pass is not sent over the wire, and is only used for the checksum
+	Pass bool
+}
+
+// Checksum calculates the checksum of the frame, which is used from b323 onwards.
+// Every version with score frames uses the same formula, and the ScoreV2 portions
+// are not part of it. Use BanchoIO.ScoreFrameChecksum to check if a version
+// supports score frames at all.
 func (sf *ScoreFrame) Checksum() string {
 	hash := md5.Sum([]byte(
 		strconv.FormatUint(uint64(sf.Time), 10) +
-			"false" + // "pass" ?
+			strconv.FormatBool(sf.Pass) +
 			strconv.Itoa(int(sf.Total300)) +
 			strconv.Itoa(int(sf.Total50)) +
 			strconv.Itoa(int(sf.TotalGeki)) +
@@ -191,7 +216,7 @@ func (s *MatchSlot) HasPlayer() bool {
 }
 
 type MatchJoin struct {
-	MatchId  int32
+	MatchId  int32 `bancho:"int32,since=298"`
 	Password string
 }
 
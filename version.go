@@ -0,0 +1,110 @@
+package chio
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+const (
+	StreamStable      = "stable"
+	StreamBeta        = "beta"
+	StreamCuttingEdge = "cuttingedge"
+	StreamTest        = "test"
+	StreamTourney     = "tourney"
+	StreamDev         = "dev"
+)
+
+// clientVersionPattern matches version strings like
+// "b282", "b20130815.2" or "b20190716cuttingedge"
+var clientVersionPattern = regexp.MustCompile(`^b(\d+)(?:\.(\d+))?([a-z]*)$`)
+
+// ClientVersion represents a parsed osu! client version string
+type ClientVersion struct {
+	// Build is the build number, or the release date in later versions
+	Build int
+
+	// Hotfix is the hotfix number, which is 0 if there is none
+	Hotfix int
+
+	// Stream is the release stream, e.g. "stable" or "cuttingedge"
+	Stream string
+}
+
+// ParseClientVersion parses a version string that was sent by the client,
+// e.g. "b282", "b20130815.2", "b20190716cuttingedge" or "b20121223test"
+func ParseClientVersion(version string) (ClientVersion, error) {
+	match := clientVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return ClientVersion{}, fmt.Errorf("invalid client version '%s'", version)
+	}
+
+	build, err := strconv.Atoi(match[1])
+	if err != nil {
+		return ClientVersion{}, fmt.Errorf("invalid client version '%s'", version)
+	}
+
+	result := ClientVersion{Build: build, Stream: StreamStable}
+
+	if match[2] != "" {
+		result.Hotfix, err = strconv.Atoi(match[2])
+		if err != nil {
+			return ClientVersion{}, fmt.Errorf("invalid client version '%s'", version)
+		}
+	}
+
+	if match[3] != "" {
+		result.Stream = match[3]
+	}
+
+	return result, nil
+}
+
+// String formats the version the same way as the client does
+func (version ClientVersion) String() string {
+	result := "b" + strconv.Itoa(version.Build)
+
+	if version.Hotfix > 0 {
+		result += "." + strconv.Itoa(version.Hotfix)
+	}
+
+	if version.Stream != "" && version.Stream != StreamStable {
+		result += version.Stream
+	}
+
+	return result
+}
+
+// Compare returns -1, 0 or +1 depending on whether the version is older,
+// equal or newer than the other version. The stream is not taken into account.
+func (version ClientVersion) Compare(other ClientVersion) int {
+	if version.Build != other.Build {
+		return compareInt(version.Build, other.Build)
+	}
+	return compareInt(version.Hotfix, other.Hotfix)
+}
+
+// Before checks if the version is older than the other version
+func (version ClientVersion) Before(other ClientVersion) bool {
+	return version.Compare(other) < 0
+}
+
+// After checks if the version is newer than the other version
+func (version ClientVersion) After(other ClientVersion) bool {
+	return version.Compare(other) > 0
+}
+
+// ClientInterface returns the BanchoIO that implements this version
+func (version ClientVersion) ClientInterface() BanchoIO {
+	return GetClientInterface(version.Build)
+}
+
+func compareInt(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
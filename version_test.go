@@ -0,0 +1,29 @@
+package chio
+
+import "testing"
+
+func TestParseClientVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected ClientVersion
+	}{
+		{"b282", ClientVersion{Build: 282, Stream: StreamStable}},
+		{"b20130815.2", ClientVersion{Build: 20130815, Hotfix: 2, Stream: StreamStable}},
+		{"b20190716cuttingedge", ClientVersion{Build: 20190716, Stream: StreamCuttingEdge}},
+		{"b20121223test", ClientVersion{Build: 20121223, Stream: StreamTest}},
+	}
+
+	for _, test := range tests {
+		version, err := ParseClientVersion(test.version)
+		if err != nil {
+			t.Errorf("%s: %v", test.version, err)
+			continue
+		}
+		if version != test.expected {
+			t.Errorf("%s: expected %#v, got %#v", test.version, test.expected, version)
+		}
+		if version.String() != test.version {
+			t.Errorf("%s: formatted as %s", test.version, version.String())
+		}
+	}
+}